@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -59,11 +60,25 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 	// Header validity is known at this point. Here we verify that uncles, transactions
 	// and withdrawals given in the block body match the header.
 	header := block.Header()
-	if err := v.engine.VerifyUncles(v.bc, block); err != nil {
-		return err
+	if v.config.TerminalTotalDifficulty != nil && header.Difficulty != nil && header.Difficulty.Sign() == 0 {
+		// Post-merge blocks cannot contain uncles, so there is no need to run
+		// the full uncle verifier, only to check that the body is empty.
+		if len(block.Uncles()) > 0 {
+			return fmt.Errorf("uncles present in post-merge block (%d)", len(block.Uncles()))
+		}
+		if header.UncleHash != types.EmptyUncleHash {
+			return fmt.Errorf("uncle root hash mismatch (header value %x, expected %x)", header.UncleHash, types.EmptyUncleHash)
+		}
+	} else {
+		if err := v.engine.VerifyUncles(v.bc, block); err != nil {
+			return err
+		}
+		if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash {
+			return fmt.Errorf("uncle root hash mismatch (header value %x, calculated %x)", header.UncleHash, hash)
+		}
 	}
-	if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash {
-		return fmt.Errorf("uncle root hash mismatch (header value %x, calculated %x)", header.UncleHash, hash)
+	if max := v.config.MaxTxPerBlock; max > 0 && uint64(len(block.Transactions())) > max {
+		return fmt.Errorf("%w: have %d, max %d", ErrTooManyTransactions, len(block.Transactions()), max)
 	}
 	if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != header.TxHash {
 		return fmt.Errorf("transaction root hash mismatch (header value %x, calculated %x)", header.TxHash, hash)
@@ -77,6 +92,13 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 		if hash := types.DeriveSha(block.Withdrawals(), trie.NewStackTrie(nil)); hash != *header.WithdrawalsHash {
 			return fmt.Errorf("withdrawals root hash mismatch (header value %x, calculated %x)", *header.WithdrawalsHash, hash)
 		}
+		if v.config.RejectZeroAddressWithdrawals {
+			for _, w := range block.Withdrawals() {
+				if w.Address == (common.Address{}) {
+					return fmt.Errorf("%w: index %d", ErrZeroAddressWithdrawal, w.Index)
+				}
+			}
+		}
 	} else if block.Withdrawals() != nil {
 		// Withdrawals are not allowed prior to Shanghai fork
 		return errors.New("withdrawals present in block body")
@@ -143,6 +165,21 @@ func (v *BlockValidator) ValidateState(block *types.Block, statedb *state.StateD
 	return nil
 }
 
+// NextGasLimit computes the gas limit of the next block after parent, keeping it
+// within the [floor, ceil] range. A parent limit inside the range is retained,
+// whereas one outside of it is moved towards the nearest bound as fast as the
+// protocol allows.
+func NextGasLimit(parent *types.Header, floor, ceil uint64) uint64 {
+	desired := parent.GasLimit
+	if desired < floor {
+		desired = floor
+	}
+	if desired > ceil {
+		desired = ceil
+	}
+	return CalcGasLimit(parent.GasLimit, desired)
+}
+
 // CalcGasLimit computes the gas limit of the next block after parent. It aims
 // to keep the baseline gas close to the provided target, and increase it towards
 // the target if the baseline gas is lower.
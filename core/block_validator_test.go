@@ -17,6 +17,7 @@
 package core
 
 import (
+	"errors"
 	"math/big"
 	"runtime"
 	"testing"
@@ -347,6 +348,143 @@ func testHeaderConcurrentAbortion(t *testing.T, threads int) {
 	}
 }
 
+// Tests that post-merge block bodies are validated without the uncle verifier,
+// but that uncles smuggled into them are still rejected.
+func TestPostMergeBodyUncles(t *testing.T) {
+	var (
+		config = *params.TestChainConfig
+		gspec  = &Genesis{Config: &config, Difficulty: common.Big0}
+		engine = beacon.New(ethash.NewFaker())
+	)
+	config.TerminalTotalDifficulty = common.Big0
+	config.TerminalTotalDifficultyPassed = true
+
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 2, func(i int, gen *BlockGen) {
+		gen.SetPoS()
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks[:1]); err != nil {
+		t.Fatalf("failed to insert parent block: %v", err)
+	}
+	block := blocks[1]
+	if block.Difficulty().Sign() != 0 {
+		t.Fatalf("block difficulty mismatch: have %v, want 0", block.Difficulty())
+	}
+	if err := chain.Validator().ValidateBody(block); err != nil {
+		t.Fatalf("valid post-merge body rejected: %v", err)
+	}
+	// Attach an uncle to the body, keeping the header intact
+	uncle := &types.Header{Number: big.NewInt(1), Difficulty: common.Big0}
+	if err := chain.Validator().ValidateBody(block.WithBody(block.Transactions(), []*types.Header{uncle})); err == nil {
+		t.Fatalf("post-merge body with uncles accepted")
+	}
+	// Commit to a non-empty uncle set in the header, keeping the body empty
+	header := block.Header()
+	header.UncleHash = types.CalcUncleHash([]*types.Header{uncle})
+	if err := chain.Validator().ValidateBody(types.NewBlockWithHeader(header).WithBody(block.Transactions(), nil)); err == nil {
+		t.Fatalf("post-merge header with non-empty uncle hash accepted")
+	}
+}
+
+// Tests that blocks with more transactions than the configured maximum are
+// rejected, while blocks at the limit are accepted.
+func TestMaxTxPerBlock(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		config = *params.TestChainConfig
+		gspec  = &Genesis{
+			Config: &config,
+			Alloc:  GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		engine = ethash.NewFaker()
+		signer = types.LatestSigner(&config)
+	)
+	config.MaxTxPerBlock = 2
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	for _, count := range []int{2, 3} {
+		_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 1, func(i int, gen *BlockGen) {
+			for nonce := 0; nonce < count; nonce++ {
+				tx, _ := types.SignTx(types.NewTransaction(uint64(nonce), common.Address{0x01}, big.NewInt(1), params.TxGas, gen.header.BaseFee, nil), signer, key)
+				gen.AddTx(tx)
+			}
+		})
+		err := chain.Validator().ValidateBody(blocks[0])
+		switch {
+		case count <= int(config.MaxTxPerBlock) && err != nil:
+			t.Errorf("block with %d transactions rejected: %v", count, err)
+		case count > int(config.MaxTxPerBlock) && !errors.Is(err, ErrTooManyTransactions):
+			t.Errorf("block with %d transactions: error mismatch: have %v, want %v", count, err, ErrTooManyTransactions)
+		}
+	}
+}
+
+// Tests that withdrawals to the zero address are only rejected if the chain
+// configuration opts into doing so.
+func TestZeroAddressWithdrawals(t *testing.T) {
+	for _, reject := range []bool{false, true} {
+		var (
+			config = *params.TestChainConfig
+			gspec  = &Genesis{Config: &config, Difficulty: common.Big0}
+			engine = beacon.New(ethash.NewFaker())
+		)
+		config.TerminalTotalDifficulty = common.Big0
+		config.TerminalTotalDifficultyPassed = true
+		config.ShanghaiTime = new(uint64)
+		config.RejectZeroAddressWithdrawals = reject
+
+		_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 1, func(i int, gen *BlockGen) {
+			gen.SetPoS()
+			gen.AddWithdrawal(&types.Withdrawal{Validator: 1, Address: common.Address{0x01}, Amount: 1})
+			gen.AddWithdrawal(&types.Withdrawal{Validator: 2, Address: common.Address{}, Amount: 1})
+		})
+		chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+		if err != nil {
+			t.Fatalf("failed to create tester chain: %v", err)
+		}
+		err = chain.Validator().ValidateBody(blocks[0])
+		switch {
+		case !reject && err != nil:
+			t.Errorf("zero address withdrawal rejected by default: %v", err)
+		case reject && !errors.Is(err, ErrZeroAddressWithdrawal):
+			t.Errorf("zero address withdrawal error mismatch: have %v, want %v", err, ErrZeroAddressWithdrawal)
+		}
+		chain.Stop()
+	}
+}
+
+func TestNextGasLimit(t *testing.T) {
+	var (
+		floor = uint64(20000000)
+		ceil  = uint64(30000000)
+	)
+	for i, tc := range []struct {
+		parent uint64
+		want   uint64
+	}{
+		{10000000, 10000000 + 10000000/params.GasLimitBoundDivisor - 1}, // Below floor, raise as fast as possible
+		{floor - 1000, floor}, // Just below floor, raise to floor
+		{25000000, 25000000},  // Within range, keep as is
+		{ceil + 1000, ceil},   // Just above ceil, lower to ceil
+		{40000000, 40000000 - 40000000/params.GasLimitBoundDivisor + 1}, // Above ceil, lower as fast as possible
+	} {
+		if have := NextGasLimit(&types.Header{GasLimit: tc.parent}, floor, ceil); have != tc.want {
+			t.Errorf("test %d: gas limit mismatch: have %d, want %d", i, have, tc.want)
+		}
+	}
+}
+
 func TestCalcGasLimit(t *testing.T) {
 	for i, tc := range []struct {
 		pGasLimit uint64
@@ -135,6 +135,15 @@ func TestGenerateWithdrawalChain(t *testing.T) {
 			withdrawalIndex += 1
 		}
 	}
+	// ensure the withdrawals were credited in wei, 1e9 per gwei
+	state, err := blockchain.State()
+	if err != nil {
+		t.Fatalf("failed to retrieve head state: %v", err)
+	}
+	want := new(big.Int).Mul(big.NewInt(2*(1337+1)), big.NewInt(params.GWei))
+	if have := state.GetBalance(common.Address{0xee}); have.Cmp(want) != 0 {
+		t.Fatalf("withdrawal recipient balance mismatch: have %v, want %v", have, want)
+	}
 }
 
 func ExampleGenerateChain() {
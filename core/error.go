@@ -32,6 +32,14 @@ var (
 	// ErrNoGenesis is returned when there is no Genesis Block.
 	ErrNoGenesis = errors.New("genesis not found in chain")
 
+	// ErrTooManyTransactions is returned if a block contains more transactions
+	// than allowed by the chain configuration.
+	ErrTooManyTransactions = errors.New("too many transactions in block")
+
+	// ErrZeroAddressWithdrawal is returned if a block contains a withdrawal to the
+	// zero address while the chain configuration disallows them.
+	ErrZeroAddressWithdrawal = errors.New("withdrawal to zero address")
+
 	errSideChainReceipts = errors.New("side blocks can't be accepted as ancient chain data")
 )
 
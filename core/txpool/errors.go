@@ -16,7 +16,11 @@
 
 package txpool
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/core"
+)
 
 var (
 	// ErrAlreadyKnown is returned if the transactions is already contained
@@ -34,6 +38,10 @@ var (
 	// with a different one without the required price bump.
 	ErrReplaceUnderpriced = errors.New("replacement transaction underpriced")
 
+	// ErrBlobFeeCapTooLow is returned if a blob transaction's blob fee cap is below
+	// the blob fee required for inclusion into the next block.
+	ErrBlobFeeCapTooLow = errors.New("blob fee cap too low")
+
 	// ErrGasLimit is returned if a transaction's requested gas limit exceeds the
 	// maximum allowance of the current block.
 	ErrGasLimit = errors.New("exceeds block gas limit")
@@ -51,3 +59,58 @@ var (
 	// transaction. Future transactions should only be able to replace other future transactions.
 	ErrFutureReplacePending = errors.New("future transaction tries to replace pending")
 )
+
+// temporaryErrors are the transaction rejection reasons caused by the current
+// state of the pool or the chain, which may resolve by themselves later on.
+var temporaryErrors = []error{
+	ErrAlreadyKnown,
+	ErrUnderpriced,
+	ErrReplaceUnderpriced,
+	ErrFutureReplacePending,
+	ErrGasLimit,
+	ErrBlobFeeCapTooLow,
+	core.ErrNonceTooHigh,
+	core.ErrInsufficientFunds,
+}
+
+// RejectionError is the reason a transaction was rejected by the pool, along
+// with whether the rejection is temporary (e.g. nonce gap, underpriced) and the
+// transaction might be accepted later, or permanent (e.g. invalid signature,
+// oversized) and the transaction will never be accepted.
+type RejectionError struct {
+	err       error
+	temporary bool
+}
+
+// NewRejectionError classifies a transaction rejection reason. Errors that are
+// already classified keep their classification, unknown ones are deemed
+// permanent.
+func NewRejectionError(err error) *RejectionError {
+	var rerr *RejectionError
+	if errors.As(err, &rerr) {
+		return &RejectionError{err: err, temporary: rerr.temporary}
+	}
+	for _, temp := range temporaryErrors {
+		if errors.Is(err, temp) {
+			return &RejectionError{err: err, temporary: true}
+		}
+	}
+	return &RejectionError{err: err}
+}
+
+// NewTemporaryError marks a subpool specific rejection reason as temporary.
+func NewTemporaryError(err error) *RejectionError {
+	return &RejectionError{err: err, temporary: true}
+}
+
+// Error implements error, returning the message of the underlying reason.
+func (e *RejectionError) Error() string { return e.err.Error() }
+
+// Unwrap returns the underlying rejection reason.
+func (e *RejectionError) Unwrap() error { return e.err }
+
+// IsTemporary reports whether the transaction might be accepted later on.
+func (e *RejectionError) IsTemporary() bool { return e.temporary }
+
+// IsPermanent reports whether the transaction will never be accepted.
+func (e *RejectionError) IsPermanent() bool { return !e.temporary }
@@ -0,0 +1,85 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core"
+)
+
+// Tests that transaction rejection reasons are classified correctly, also when
+// wrapped with additional context.
+func TestRejectionErrorClassification(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err       error
+		temporary bool
+	}{
+		{ErrAlreadyKnown, true},
+		{ErrUnderpriced, true},
+		{ErrReplaceUnderpriced, true},
+		{ErrFutureReplacePending, true},
+		{ErrGasLimit, true},
+		{ErrBlobFeeCapTooLow, true},
+		{core.ErrNonceTooHigh, true},
+		{core.ErrInsufficientFunds, true},
+
+		{ErrInvalidSender, false},
+		{ErrNegativeValue, false},
+		{ErrOversizedData, false},
+		{core.ErrNonceTooLow, false},
+		{core.ErrIntrinsicGas, false},
+		{core.ErrTxTypeNotSupported, false},
+		{core.ErrTipAboveFeeCap, false},
+		{core.ErrMaxInitCodeSizeExceeded, false},
+		{errors.New("unknown"), false},
+	}
+	for i, tt := range tests {
+		for _, err := range []error{tt.err, fmt.Errorf("%w: context", tt.err)} {
+			rerr := NewRejectionError(err)
+			if rerr.IsTemporary() != tt.temporary || rerr.IsPermanent() == tt.temporary {
+				t.Errorf("test %d: classification mismatch for %q: temporary %v, want %v", i, err, rerr.IsTemporary(), tt.temporary)
+			}
+			if !errors.Is(rerr, tt.err) {
+				t.Errorf("test %d: wrapped reason lost for %q", i, err)
+			}
+			if rerr.Error() != err.Error() {
+				t.Errorf("test %d: message mismatch: have %q, want %q", i, rerr.Error(), err.Error())
+			}
+		}
+	}
+}
+
+// Tests that subpool specific reasons can be marked temporary, and that the
+// marking survives reclassification.
+func TestTemporaryRejectionError(t *testing.T) {
+	t.Parallel()
+
+	reason := errors.New("subpool full")
+	err := fmt.Errorf("wrapped: %w", NewTemporaryError(reason))
+
+	if rerr := NewRejectionError(err); !rerr.IsTemporary() {
+		t.Errorf("temporary marking lost on reclassification")
+	}
+	if rerr := NewRejectionError(err); !errors.Is(rerr, reason) || rerr.Error() != err.Error() {
+		t.Errorf("underlying reason lost on reclassification: %v", rerr)
+	}
+}
@@ -18,15 +18,19 @@
 package legacypool
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"math"
 	"math/big"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/common/prque"
 	"github.com/ethereum/go-ethereum/consensus/misc"
 	"github.com/ethereum/go-ethereum/core"
@@ -61,6 +65,10 @@ var (
 	// ErrTxPoolOverflow is returned if the transaction pool is full and can't accept
 	// another remote transaction.
 	ErrTxPoolOverflow = errors.New("txpool is full")
+
+	// ErrTxGasLimit is returned if a transaction's requested gas limit exceeds the
+	// maximum allowance configured for a single transaction in the pool.
+	ErrTxGasLimit = errors.New("exceeds transaction gas limit")
 )
 
 var (
@@ -88,6 +96,7 @@ var (
 	invalidTxMeter     = metrics.NewRegisteredMeter("txpool/invalid", nil)
 	underpricedTxMeter = metrics.NewRegisteredMeter("txpool/underpriced", nil)
 	overflowedTxMeter  = metrics.NewRegisteredMeter("txpool/overflowed", nil)
+	overBudgetTxMeter  = metrics.NewRegisteredMeter("txpool/overbudget", nil)
 
 	// throttleTxMeter counts how many transactions are rejected due to too-many-changes between
 	// txpool reorgs.
@@ -138,6 +147,13 @@ type Config struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	MaxTxGas uint64 // Maximum gas limit of a single transaction, on top of the block limit (0 = disabled)
+
+	FairEviction bool // Whether to evict from accounts over their queue allowance before evicting by price
+
+	StatsInterval time.Duration // Time interval to sample the pool occupancy for debugging (0 = disabled)
+	StatsHistory  int           // Number of pool occupancy samples to retain
 }
 
 // DefaultConfig contains the default configurations for the transaction pool.
@@ -154,6 +170,8 @@ var DefaultConfig = Config{
 	GlobalQueue:  1024,
 
 	Lifetime: 3 * time.Hour,
+
+	StatsHistory: 360,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -192,6 +210,10 @@ func (config *Config) sanitize() Config {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultConfig.Lifetime)
 		conf.Lifetime = DefaultConfig.Lifetime
 	}
+	if conf.StatsInterval > 0 && conf.StatsHistory < 1 {
+		log.Warn("Sanitizing invalid txpool stats history", "provided", conf.StatsHistory, "updated", DefaultConfig.StatsHistory)
+		conf.StatsHistory = DefaultConfig.StatsHistory
+	}
 	return conf
 }
 
@@ -216,8 +238,9 @@ type LegacyPool struct {
 	currentState  *state.StateDB               // Current state in the blockchain head
 	pendingNonces *noncer                      // Pending state tracking virtual nonces
 
-	locals  *accountSet // Set of local transaction to exempt from eviction rules
-	journal *journal    // Journal of local transaction to back up to disk
+	locals       *accountSet                                 // Set of local transaction to exempt from eviction rules
+	localSenders atomic.Pointer[map[common.Address]struct{}] // Snapshot of the local accounts for lock-free checks
+	journal      *journal                                    // Journal of local transaction to back up to disk
 
 	pending map[common.Address]*list     // All currently processable transactions
 	queue   map[common.Address]*list     // Queued but non-processable transactions
@@ -234,6 +257,9 @@ type LegacyPool struct {
 	initDoneCh      chan struct{}  // is closed once the pool is initialized (for tests)
 
 	changesSinceReorg int // A counter for how many drops we've performed in-between reorg.
+
+	clock   mclock.Clock  // Clock driving the occupancy sampling, replaceable in tests
+	history *statsHistory // Recent pool occupancy samples (nil if disabled)
 }
 
 type txpoolResetRequest struct {
@@ -262,17 +288,24 @@ func New(config Config, chain BlockChain) *LegacyPool {
 		reorgDoneCh:     make(chan chan struct{}),
 		reorgShutdownCh: make(chan struct{}),
 		initDoneCh:      make(chan struct{}),
+		clock:           mclock.System{},
 	}
 	pool.locals = newAccountSet(pool.signer)
-	for _, addr := range config.Locals {
-		log.Info("Setting new local account", "address", addr)
-		pool.locals.add(addr)
+	pool.localSenders.Store(new(map[common.Address]struct{}))
+	if !config.NoLocals {
+		for _, addr := range config.Locals {
+			log.Info("Setting new local account", "address", addr)
+			pool.trackLocal(addr)
+		}
 	}
 	pool.priced = newPricedList(pool.all)
 
 	if !config.NoLocals && config.Journal != "" {
 		pool.journal = newTxJournal(config.Journal)
 	}
+	if config.StatsInterval > 0 {
+		pool.history = newStatsHistory(config.StatsHistory)
+	}
 	return pool
 }
 
@@ -294,7 +327,21 @@ func (pool *LegacyPool) Filter(tx *types.Transaction) bool {
 func (pool *LegacyPool) Init(gasTip *big.Int, head *types.Header) error {
 	// Set the basic pool parameters
 	pool.gasTip.Store(gasTip)
-	pool.reset(nil, head)
+
+	// Initialize the state with head block, or fallback to empty one in
+	// case the head state is not available (might occur when node is not
+	// fully synced).
+	statedb, err := pool.chain.StateAt(head.Root)
+	if err != nil {
+		log.Warn("Failed to load txpool head state, using empty state", "number", head.Number, "hash", head.Hash(), "err", err)
+		statedb, err = pool.chain.StateAt(types.EmptyRootHash)
+	}
+	if err != nil {
+		return err
+	}
+	pool.currentHead.Store(head)
+	pool.currentState = statedb
+	pool.pendingNonces = newNoncer(statedb)
 
 	// Start the reorg loop early, so it can handle requests generated during
 	// journal loading.
@@ -312,6 +359,11 @@ func (pool *LegacyPool) Init(gasTip *big.Int, head *types.Header) error {
 	}
 	pool.wg.Add(1)
 	go pool.loop()
+
+	if pool.history != nil {
+		pool.wg.Add(1)
+		go pool.statsLoop()
+	}
 	return nil
 }
 
@@ -385,6 +437,44 @@ func (pool *LegacyPool) loop() {
 	}
 }
 
+// statsLoop periodically records the pool occupancy into the stats history.
+func (pool *LegacyPool) statsLoop() {
+	defer pool.wg.Done()
+
+	timer := pool.clock.NewTimer(pool.config.StatsInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-pool.reorgShutdownCh:
+			return
+
+		case <-timer.C():
+			pool.mu.RLock()
+			pending, queued := pool.stats()
+			slots := pool.all.Slots()
+			pool.mu.RUnlock()
+
+			pool.history.add(StatsSample{
+				Time:    pool.clock.Now(),
+				Pending: pending,
+				Queued:  queued,
+				Slots:   slots,
+			})
+			timer.Reset(pool.config.StatsInterval)
+		}
+	}
+}
+
+// StatsHistory retrieves the recorded pool occupancy samples, oldest first. It
+// returns nil if occupancy sampling is disabled.
+func (pool *LegacyPool) StatsHistory() []StatsSample {
+	if pool.history == nil {
+		return nil
+	}
+	return pool.history.flatten()
+}
+
 // Close terminates the transaction pool.
 func (pool *LegacyPool) Close() error {
 	// Unsubscribe all subscriptions registered from txpool
@@ -453,6 +543,12 @@ func (pool *LegacyPool) Stats() (int, int) {
 	return pool.stats()
 }
 
+// SlotStats retrieves the number of transaction slots currently in use by the
+// pool along with the total number of slots it is allowed to hold.
+func (pool *LegacyPool) SlotStats() (int, uint64) {
+	return pool.all.Slots(), pool.config.GlobalSlots + pool.config.GlobalQueue
+}
+
 // stats retrieves the current pool stats, namely the number of pending and the
 // number of queued (non-executable) transactions.
 func (pool *LegacyPool) stats() (int, int) {
@@ -467,6 +563,25 @@ func (pool *LegacyPool) stats() (int, int) {
 	return pending, queued
 }
 
+// CountBySender retrieves the number of pending and queued transactions of each
+// account in the pool. It is a cheaper alternative to Content when only the
+// counts are needed.
+func (pool *LegacyPool) CountBySender() map[common.Address]txpool.SenderStats {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	counts := make(map[common.Address]txpool.SenderStats, len(pool.pending)+len(pool.queue))
+	for addr, list := range pool.pending {
+		counts[addr] = txpool.SenderStats{Pending: list.Len()}
+	}
+	for addr, list := range pool.queue {
+		stats := counts[addr]
+		stats.Queued = list.Len()
+		counts[addr] = stats
+	}
+	return counts
+}
+
 // Content retrieves the data content of the transaction pool, returning all the
 // pending as well as queued transactions, grouped by account and sorted by nonce.
 func (pool *LegacyPool) Content() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction) {
@@ -484,6 +599,26 @@ func (pool *LegacyPool) Content() (map[common.Address][]*types.Transaction, map[
 	return pending, queued
 }
 
+// Snapshot retrieves a consistent view of the pool's pending and queued
+// transactions along with the matching stats, all captured under one lock.
+func (pool *LegacyPool) Snapshot() *txpool.Snapshot {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	snap := &txpool.Snapshot{
+		Pending: make(map[common.Address][]*types.Transaction, len(pool.pending)),
+		Queued:  make(map[common.Address][]*types.Transaction, len(pool.queue)),
+	}
+	for addr, list := range pool.pending {
+		snap.Pending[addr] = list.Flatten()
+	}
+	for addr, list := range pool.queue {
+		snap.Queued[addr] = list.Flatten()
+	}
+	snap.PendingCount, snap.QueuedCount = pool.stats()
+	return snap
+}
+
 // ContentFrom retrieves the data content of the transaction pool, returning the
 // pending as well as queued transactions of this address, grouped by nonce.
 func (pool *LegacyPool) ContentFrom(addr common.Address) ([]*types.Transaction, []*types.Transaction) {
@@ -501,6 +636,36 @@ func (pool *LegacyPool) ContentFrom(addr common.Address) ([]*types.Transaction,
 	return pending, queued
 }
 
+// DumpSender returns a human readable listing of the pending and queued
+// transactions of an account, meant for debugging why a transaction is stuck.
+func (pool *LegacyPool) DumpSender(addr common.Address) string {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	var (
+		pending []*types.Transaction
+		queued  []*types.Transaction
+	)
+	if list, ok := pool.pending[addr]; ok {
+		pending = list.Flatten()
+	}
+	if list, ok := pool.queue[addr]; ok {
+		queued = list.Flatten()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "account %v: pool nonce %d, %d pending, %d queued\n", addr, pool.pendingNonces.get(addr), len(pending), len(queued))
+	for _, txs := range []struct {
+		status string
+		list   []*types.Transaction
+	}{{"pending", pending}, {"queued", queued}} {
+		for _, tx := range txs.list {
+			fmt.Fprintf(&b, "  nonce=%d status=%s hash=%v cost=%v feecap=%v tip=%v gas=%d\n",
+				tx.Nonce(), txs.status, tx.Hash(), tx.Cost(), tx.GasFeeCap(), tx.GasTipCap(), tx.Gas())
+		}
+	}
+	return b.String()
+}
+
 // Pending retrieves all currently processable transactions, grouped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
@@ -512,14 +677,106 @@ func (pool *LegacyPool) Pending(enforceTips bool) map[common.Address][]*types.Tr
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
-	pending := make(map[common.Address][]*types.Transaction, len(pool.pending))
+	return pool.pendingTxs(enforceTips, nil)
+}
+
+// PendingIterator returns an iterator over all currently processable transactions,
+// yielding them in price and nonce order. Only the per-account lists are copied
+// under the pool lock; the ordering and the tip enforcement are done lazily as
+// transactions are retrieved, so taking only the first few transactions is cheap
+// compared to retrieving the entire pending set.
+//
+// The iterator operates on a snapshot of the pending set at the time of the call
+// and is not safe for concurrent use.
+func (pool *LegacyPool) PendingIterator(enforceTips bool) txpool.PendingIterator {
+	pool.mu.Lock()
+	var (
+		baseFee = pool.priced.urgent.baseFee
+		gasTip  = pool.gasTip.Load()
+		pending = make(map[common.Address][]*types.Transaction, len(pool.pending))
+		locals  = make(map[common.Address]bool)
+	)
 	for addr, list := range pool.pending {
-		txs := list.Flatten()
+		pending[addr] = list.Flatten()
+		if enforceTips && pool.locals.contains(addr) {
+			locals[addr] = true
+		}
+	}
+	pool.mu.Unlock()
+
+	txs := types.NewTransactionsByPriceAndNonce(pool.signer, pending, baseFee)
+	return func() (*types.Transaction, *big.Int, bool) {
+		for {
+			tx := txs.Peek()
+			if tx == nil {
+				return nil, nil, false
+			}
+			// If the miner requests tip enforcement, skip the rest of the account
+			// from the first transaction paying too little
+			if enforceTips && tx.EffectiveGasTipIntCmp(gasTip, baseFee) < 0 {
+				if from, _ := types.Sender(pool.signer, tx); !locals[from] {
+					txs.Pop()
+					continue
+				}
+			}
+			txs.Shift()
+			return tx, tx.EffectiveGasTipValue(baseFee), true
+		}
+	}
+}
+
+// PendingForBlock retrieves the currently processable transactions that fit into
+// a block with the given gas limit, grouped by origin account and sorted by nonce.
+// Transactions are picked in price and nonce order, the same way the miner would;
+// once an account's next transaction doesn't fit the remaining gas, the rest of
+// that account's transactions are skipped too to keep the nonces contiguous.
+func (pool *LegacyPool) PendingForBlock(enforceTips bool, gasLimit uint64) map[common.Address][]*types.Transaction {
+	pool.mu.Lock()
+	pending, baseFee := pool.pendingTxs(enforceTips, nil), pool.priced.urgent.baseFee
+	pool.mu.Unlock()
+
+	var (
+		txs    = types.NewTransactionsByPriceAndNonce(pool.signer, pending, baseFee)
+		picked = make(map[common.Address][]*types.Transaction)
+	)
+	for gasLimit >= params.TxGas {
+		tx := txs.Peek()
+		if tx == nil {
+			break
+		}
+		if tx.Gas() > gasLimit {
+			txs.Pop()
+			continue
+		}
+		from, _ := types.Sender(pool.signer, tx) // already validated by the pool
+		picked[from] = append(picked[from], tx)
+
+		gasLimit -= tx.Gas()
+		txs.Shift()
+	}
+	return picked
+}
 
-		// If the miner requests tip enforcement, cap the lists now
-		if enforceTips && !pool.locals.contains(addr) {
+// pendingTxs retrieves all currently processable transactions, grouped by origin
+// account and sorted by nonce. If a filter is given, each account's list is cut
+// off at the first transaction rejected by it.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *LegacyPool) pendingTxs(enforceTips bool, filter func(*types.Transaction) bool) map[common.Address][]*types.Transaction {
+	pending := make(map[common.Address][]*types.Transaction, len(pool.pending))
+	for addr, list := range pool.pending {
+		var (
+			txs     = list.Flatten()
+			enforce = enforceTips && !pool.locals.contains(addr)
+		)
+		// If the miner requests tip enforcement or filtering, cap the lists now
+		if enforce || filter != nil {
 			for i, tx := range txs {
-				if tx.EffectiveGasTipIntCmp(pool.gasTip.Load(), pool.priced.urgent.baseFee) < 0 {
+				if enforce && tx.EffectiveGasTipIntCmp(pool.gasTip.Load(), pool.priced.urgent.baseFee) < 0 {
+					txs = txs[:i]
+					break
+				}
+				if filter != nil && !filter(tx) {
 					txs = txs[:i]
 					break
 				}
@@ -532,6 +789,20 @@ func (pool *LegacyPool) Pending(enforceTips bool) map[common.Address][]*types.Tr
 	return pending
 }
 
+// PendingFiltered retrieves all currently processable transactions accepted by
+// the given filter, grouped by origin account and sorted by nonce. The returned
+// transaction set is a copy and can be freely modified by calling code.
+//
+// To keep the nonces of an account contiguous, its transaction list is cut off
+// at the first transaction rejected by the filter. The enforceTips parameter
+// caps the lists the same way as in Pending.
+func (pool *LegacyPool) PendingFiltered(enforceTips bool, filter func(*types.Transaction) bool) map[common.Address][]*types.Transaction {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return pool.pendingTxs(enforceTips, filter)
+}
+
 // Locals retrieves the accounts currently considered local by the pool.
 func (pool *LegacyPool) Locals() []common.Address {
 	pool.mu.Lock()
@@ -540,6 +811,28 @@ func (pool *LegacyPool) Locals() []common.Address {
 	return pool.locals.flatten()
 }
 
+// LocalTxs retrieves all the transactions of local accounts currently in the
+// pool, e.g. to re-announce them to peers on startup. The transactions are sorted
+// by sender address and then by nonce.
+func (pool *LegacyPool) LocalTxs() types.Transactions {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	local := pool.local()
+	addrs := make([]common.Address, 0, len(local))
+	for addr := range local {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+	var txs types.Transactions
+	for _, addr := range addrs {
+		txs = append(txs, local[addr]...)
+	}
+	return txs
+}
+
 // local retrieves all currently known local transactions, grouped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
@@ -556,10 +849,36 @@ func (pool *LegacyPool) local() map[common.Address]types.Transactions {
 	return txs
 }
 
+// trackLocal marks an account as local and publishes a fresh snapshot of the
+// local accounts for the lock-free validation. The pool lock must be held.
+func (pool *LegacyPool) trackLocal(addr common.Address) {
+	pool.locals.add(addr)
+
+	senders := make(map[common.Address]struct{}, len(pool.locals.accounts))
+	for local := range pool.locals.accounts {
+		senders[local] = struct{}{}
+	}
+	pool.localSenders.Store(&senders)
+}
+
+// isLocalSender reports whether the sender of a transaction was local as of the
+// last published snapshot. It does not require the pool mutex to be held.
+func (pool *LegacyPool) isLocalSender(tx *types.Transaction) bool {
+	from, err := types.Sender(pool.signer, tx)
+	if err != nil {
+		return false
+	}
+	_, ok := (*pool.localSenders.Load())[from]
+	return ok
+}
+
 // validateTxBasics checks whether a transaction is valid according to the consensus
 // rules, but does not check state-dependent validation such as sufficient balance.
 // This check is meant as an early check which only needs to be performed once,
 // and does not require the pool mutex to be held.
+//
+// Note, the minimum tip is not enforced for senders found in the snapshot of the
+// local accounts. Their locality is confirmed under the lock in validateTx.
 func (pool *LegacyPool) validateTxBasics(tx *types.Transaction, local bool) error {
 	opts := &txpool.ValidationOptions{
 		Config: pool.chainconfig,
@@ -570,18 +889,28 @@ func (pool *LegacyPool) validateTxBasics(tx *types.Transaction, local bool) erro
 		MaxSize: txMaxSize,
 		MinTip:  pool.gasTip.Load(),
 	}
-	if local {
+	if local || pool.isLocalSender(tx) {
 		opts.MinTip = new(big.Int)
 	}
 	if err := txpool.ValidateTransaction(tx, nil, nil, nil, pool.currentHead.Load(), pool.signer, opts); err != nil {
 		return err
 	}
+	// Ensure the transaction doesn't exceed the configured per-transaction gas
+	if limit := pool.config.MaxTxGas; limit != 0 && tx.Gas() > limit {
+		return fmt.Errorf("%w: gas %d, limit %d", ErrTxGasLimit, tx.Gas(), limit)
+	}
 	return nil
 }
 
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *LegacyPool) validateTx(tx *types.Transaction, local bool) error {
+	// Ensure the gas tip is high enough to cover the requirement of the pool,
+	// unless the transaction is local by origin or by its tracked sender. This
+	// rejects transactions admitted by a stale snapshot in validateTxBasics.
+	if tip := pool.gasTip.Load(); !local && tx.GasTipCapIntCmp(tip) < 0 {
+		return fmt.Errorf("%w: tip needed %v, tip permitted %v", txpool.ErrUnderpriced, tip, tx.GasTipCap())
+	}
 	opts := &txpool.ValidationOptionsWithState{
 		State: pool.currentState,
 
@@ -607,6 +936,23 @@ func (pool *LegacyPool) validateTx(tx *types.Transaction, local bool) error {
 	return nil
 }
 
+// ValidateTx checks whether a transaction would pass the pool's validation rules
+// against the current head and state, without adding it to the pool. The local
+// flag has the same meaning as when adding the transaction.
+//
+// Note, the transaction may still be rejected on insertion if it's a known one,
+// an underpriced replacement, or if the pool is full and it's too cheap.
+func (pool *LegacyPool) ValidateTx(tx *types.Transaction, local bool) error {
+	if err := pool.validateTxBasics(tx, local); err != nil {
+		return err
+	}
+	// The state is read during validation, which is not safe to do concurrently
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return pool.validateTx(tx, local || pool.locals.containsTx(tx))
+}
+
 // add validates a transaction and inserts it into the non-executable queue for later
 // pending promotion and execution. If the transaction is a replacement for an already
 // pending or queued one, it overwrites the previous transaction if its price is higher.
@@ -636,6 +982,11 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 	// already validated by this point
 	from, _ := types.Sender(pool.signer, tx)
 
+	// If the transaction pool is full and fair eviction is enabled, try to make room
+	// for accounts within their allowance at the expense of those exceeding theirs
+	if pool.config.FairEviction && uint64(pool.all.Slots()+numSlots(tx)) > pool.config.GlobalSlots+pool.config.GlobalQueue {
+		pool.evictOverBudget(from, tx)
+	}
 	// If the transaction pool is full, discard underpriced transactions
 	if uint64(pool.all.Slots()+numSlots(tx)) > pool.config.GlobalSlots+pool.config.GlobalQueue {
 		// If the new transaction is underpriced, don't accept it
@@ -651,7 +1002,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 		// replacements to 25% of the slots
 		if pool.changesSinceReorg > int(pool.config.GlobalSlots/4) {
 			throttleTxMeter.Mark(1)
-			return false, ErrTxPoolOverflow
+			return false, txpool.NewTemporaryError(ErrTxPoolOverflow)
 		}
 
 		// New transaction is better than our worse ones, make room for it.
@@ -663,7 +1014,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 		if !isLocal && !success {
 			log.Trace("Discarding overflown transaction", "hash", hash)
 			overflowedTxMeter.Mark(1)
-			return false, ErrTxPoolOverflow
+			return false, txpool.NewTemporaryError(ErrTxPoolOverflow)
 		}
 
 		// If the new transaction is a future transaction it should never churn pending transactions
@@ -727,7 +1078,7 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 	// Mark local addresses and journal local transactions
 	if local && !pool.locals.contains(from) {
 		log.Info("Setting new local account", "address", from)
-		pool.locals.add(from)
+		pool.trackLocal(from)
 		pool.priced.Removed(pool.all.RemoteToLocals(pool.locals)) // Migrate the remotes if it's marked as local first time.
 	}
 	if isLocal {
@@ -739,6 +1090,111 @@ func (pool *LegacyPool) add(tx *types.Transaction, local bool) (replaced bool, e
 	return replaced, nil
 }
 
+// accountTxs returns the number of pending and queued transactions of an account.
+func (pool *LegacyPool) accountTxs(addr common.Address) int {
+	var count int
+	if list := pool.pending[addr]; list != nil {
+		count += list.Len()
+	}
+	if list := pool.queue[addr]; list != nil {
+		count += list.Len()
+	}
+	return count
+}
+
+// evictOverBudget tries to make room for the given transaction by dropping the
+// transactions of remote accounts which hold more pending and queued transactions
+// than their AccountQueue allowance. The largest offenders are trimmed first, each
+// from its highest nonce downwards to avoid creating nonce gaps, but never below
+// their allowance. A gapped transaction may only displace queued transactions,
+// never pending ones.
+//
+// Nothing is evicted unless the offenders can free up all the needed room, the
+// transaction is a new one from an account within its allowance and the pool is
+// not throttled. Otherwise the decision is left to the price based eviction.
+func (pool *LegacyPool) evictOverBudget(from common.Address, tx *types.Transaction) {
+	budget := int(pool.config.AccountQueue)
+	if pool.accountTxs(from) >= budget {
+		return
+	}
+	// Replacements are subject to the price bump, don't evict anything on their
+	// behalf as they might get rejected anyway
+	if list := pool.pending[from]; list != nil && list.Contains(tx.Nonce()) {
+		return
+	}
+	if list := pool.queue[from]; list != nil && list.Contains(tx.Nonce()) {
+		return
+	}
+	// Same as for the price based eviction, cap the number of changes between
+	// reorg-runs to 25% of the slots
+	if pool.changesSinceReorg > int(pool.config.GlobalSlots/4) {
+		return
+	}
+	// Gather the offenders afresh, accounts may have crossed their allowance in
+	// either direction since the last eviction
+	var (
+		slots   = pool.all.Slots() - int(pool.config.GlobalSlots+pool.config.GlobalQueue) + numSlots(tx)
+		pending = !pool.isGapped(from, tx)
+		drop    types.Transactions
+	)
+	for _, addr := range pool.gatherOverBudget(budget) {
+		excess := pool.accountTxs(addr) - budget
+
+		// Pick the highest nonce transactions, queued ones before pending ones
+		var txs types.Transactions
+		if list := pool.queue[addr]; list != nil {
+			txs = list.Flatten()
+		}
+		if list := pool.pending[addr]; list != nil && pending {
+			txs = append(list.Flatten(), txs...)
+		}
+		for i := len(txs) - 1; i >= 0 && excess > 0 && slots > 0; i-- {
+			drop = append(drop, txs[i])
+			slots -= numSlots(txs[i])
+			excess--
+		}
+		if slots <= 0 {
+			break
+		}
+	}
+	if slots > 0 {
+		return
+	}
+	for _, tx := range drop {
+		log.Trace("Discarding transaction of over budget account", "hash", tx.Hash())
+		overBudgetTxMeter.Mark(1)
+		pool.changesSinceReorg += pool.removeTx(tx.Hash(), true)
+	}
+}
+
+// gatherOverBudget returns the remote accounts holding more than the given number
+// of pending and queued transactions, largest offenders first.
+func (pool *LegacyPool) gatherOverBudget(budget int) []common.Address {
+	var (
+		offenders []common.Address
+		counts    = make(map[common.Address]int)
+	)
+	for _, txs := range []map[common.Address]*list{pool.pending, pool.queue} {
+		for addr := range txs {
+			if _, ok := counts[addr]; ok || pool.locals.contains(addr) {
+				continue
+			}
+			count := pool.accountTxs(addr)
+			counts[addr] = count
+			if count > budget {
+				offenders = append(offenders, addr)
+			}
+		}
+	}
+	sort.Slice(offenders, func(i, j int) bool {
+		if counts[offenders[i]] != counts[offenders[j]] {
+			return counts[offenders[i]] > counts[offenders[j]]
+		}
+		return bytes.Compare(offenders[i][:], offenders[j][:]) < 0
+	})
+	return offenders
+}
+
 // isGapped reports whether the given transaction is immediately executable.
 func (pool *LegacyPool) isGapped(from common.Address, tx *types.Transaction) bool {
 	// Short circuit if transaction falls within the scope of the pending list
@@ -992,6 +1448,27 @@ func (pool *LegacyPool) Status(hash common.Hash) txpool.TxStatus {
 	return txpool.TxStatusUnknown
 }
 
+// Lookup retrieves a transaction along with its current status in a single pass,
+// avoiding the cost and the race of calling Has or Get followed by Status. A
+// transaction that is neither pending nor queued is reported as not found.
+func (pool *LegacyPool) Lookup(hash common.Hash) (*types.Transaction, txpool.TxStatus, bool) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	tx := pool.all.Get(hash)
+	if tx == nil {
+		return nil, txpool.TxStatusUnknown, false
+	}
+	from, _ := types.Sender(pool.signer, tx) // already validated
+
+	if txList := pool.pending[from]; txList != nil && txList.txs.items[tx.Nonce()] != nil {
+		return tx, txpool.TxStatusPending, true
+	} else if txList := pool.queue[from]; txList != nil && txList.txs.items[tx.Nonce()] != nil {
+		return tx, txpool.TxStatusQueued, true
+	}
+	return nil, txpool.TxStatusUnknown, false
+}
+
 // Get returns a transaction if it is contained in the pool and nil otherwise.
 func (pool *LegacyPool) Get(hash common.Hash) *txpool.Transaction {
 	tx := pool.get(hash)
@@ -1006,6 +1483,12 @@ func (pool *LegacyPool) get(hash common.Hash) *types.Transaction {
 	return pool.all.Get(hash)
 }
 
+// GetTransactions returns the transactions matching the given hashes, aligned
+// positionally with the input. Transactions not contained in the pool are nil.
+func (pool *LegacyPool) GetTransactions(hashes []common.Hash) []*types.Transaction {
+	return pool.all.GetBatch(hashes)
+}
+
 // Has returns an indicator whether txpool has a transaction cached with the
 // given hash.
 func (pool *LegacyPool) Has(hash common.Hash) bool {
@@ -1327,7 +1810,9 @@ func (pool *LegacyPool) reset(oldHead, newHead *types.Header) {
 	}
 	statedb, err := pool.chain.StateAt(newHead.Root)
 	if err != nil {
-		log.Error("Failed to reset txpool state", "err", err)
+		// Keep validating against the last good state, the next head event
+		// will retry the reset
+		log.Error("Failed to reset txpool state", "number", newHead.Number, "hash", newHead.Hash(), "err", err)
 		return
 	}
 	pool.currentHead.Store(newHead)
@@ -1741,6 +2226,23 @@ func (t *lookup) Get(hash common.Hash) *types.Transaction {
 	return t.remotes[hash]
 }
 
+// GetBatch returns the transactions for a batch of hashes, acquiring the lock
+// only once. The results are aligned with the hashes, with nil for misses.
+func (t *lookup) GetBatch(hashes []common.Hash) []*types.Transaction {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	txs := make([]*types.Transaction, len(hashes))
+	for i, hash := range hashes {
+		if tx := t.locals[hash]; tx != nil {
+			txs[i] = tx
+		} else {
+			txs[i] = t.remotes[hash]
+		}
+	}
+	return txs
+}
+
 // GetLocal returns a transaction if it exists in the lookup, or nil if not found.
 func (t *lookup) GetLocal(hash common.Hash) *types.Transaction {
 	t.lock.RLock()
@@ -1853,7 +2355,12 @@ func (t *lookup) RemotesBelowTip(threshold *big.Int) types.Transactions {
 	return found
 }
 
-// numSlots calculates the number of slots needed for a single transaction.
+// numSlots calculates the number of slots needed for a single transaction. Every
+// transaction takes up at least one slot, so the slot accounting of the lookup
+// can never be bypassed, whatever size the transaction reports.
 func numSlots(tx *types.Transaction) int {
-	return int((tx.Size() + txSlotSize - 1) / txSlotSize)
+	if slots := int((tx.Size() + txSlotSize - 1) / txSlotSize); slots > 1 {
+		return slots
+	}
+	return 1
 }
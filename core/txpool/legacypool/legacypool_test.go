@@ -17,6 +17,7 @@
 package legacypool
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	crand "crypto/rand"
 	"errors"
@@ -24,11 +25,16 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
@@ -37,6 +43,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
 )
 
@@ -145,6 +152,23 @@ func setupPoolWithConfig(config *params.ChainConfig) (*LegacyPool, *ecdsa.Privat
 	return pool, key
 }
 
+// setupTxPool creates a legacy pool wrapped into the aggregating transaction pool
+// the node uses, to test the accessors forwarded through it.
+func setupTxPool() (*txpool.TxPool, *LegacyPool, *ecdsa.PrivateKey) {
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(params.TestChainConfig, 10000000, statedb, new(event.Feed))
+
+	key, _ := crypto.GenerateKey()
+	pool := New(testTxPoolConfig, blockchain)
+	txpool, err := txpool.New(new(big.Int).SetUint64(testTxPoolConfig.PriceLimit), blockchain, []txpool.SubPool{pool})
+	if err != nil {
+		panic(err)
+	}
+	// wait for the pool to initialize
+	<-pool.initDoneCh
+	return txpool, pool, key
+}
+
 // validatePoolInternals checks various consistency invariants within the pool.
 func validatePoolInternals(pool *LegacyPool) error {
 	pool.mu.RLock()
@@ -281,6 +305,451 @@ func TestStateChangeDuringReset(t *testing.T) {
 	}
 }
 
+// failingStateChain is a test chain whose state can be made unavailable, except
+// for the empty state which is always retrievable.
+type failingStateChain struct {
+	*testBlockChain
+	fail atomic.Bool
+}
+
+func (c *failingStateChain) StateAt(root common.Hash) (*state.StateDB, error) {
+	if c.fail.Load() {
+		if root == types.EmptyRootHash {
+			return state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		}
+		return nil, errors.New("state unavailable")
+	}
+	return c.testBlockChain.StateAt(root)
+}
+
+// Tests that if the state of the head is unavailable when the pool starts up, it
+// starts on an empty state instead and switches over to the real one once a head
+// with available state arrives.
+func TestInitWithMissingState(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key, _     = crypto.GenerateKey()
+		address    = crypto.PubkeyToAddress(key.PublicKey)
+		statedb, _ = state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	)
+	statedb.SetBalance(address, new(big.Int).SetUint64(params.Ether))
+	blockchain := &failingStateChain{testBlockChain: newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))}
+	blockchain.fail.Store(true)
+
+	pool := New(testTxPoolConfig, blockchain)
+	head := &types.Header{Number: new(big.Int), GasLimit: 1000000, Root: common.Hash{0x01}}
+	if err := pool.Init(new(big.Int).SetUint64(testTxPoolConfig.PriceLimit), head); err != nil {
+		t.Fatalf("failed to init pool on missing state: %v", err)
+	}
+	defer pool.Close()
+
+	// The funded account is unknown to the empty state, so its transactions
+	// can't be paid for yet
+	if err := pool.addRemoteSync(transaction(0, 100000, key)); !errors.Is(err, core.ErrInsufficientFunds) {
+		t.Fatalf("error mismatch on empty state: have %v, want %v", err, core.ErrInsufficientFunds)
+	}
+	// Reset onto a head with available state and ensure the pool recovers
+	blockchain.fail.Store(false)
+	<-pool.requestReset(nil, &types.Header{Number: big.NewInt(1), GasLimit: 1000000, BaseFee: big.NewInt(1), Root: common.Hash{0x01}})
+
+	if number := pool.currentHead.Load().Number.Uint64(); number != 1 {
+		t.Errorf("head number mismatch: have %d, want %d", number, 1)
+	}
+
+	if err := pool.addRemoteSync(transaction(0, 100000, key)); err != nil {
+		t.Fatalf("failed to add transaction after recovery: %v", err)
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Errorf("pending transaction count mismatch: have %d, want %d", pending, 1)
+	}
+}
+
+// Tests that if the state of a new head cannot be retrieved, the pool keeps on
+// validating transactions against the last good state instead of crashing.
+func TestResetWithMissingState(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key, _     = crypto.GenerateKey()
+		address    = crypto.PubkeyToAddress(key.PublicKey)
+		statedb, _ = state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	)
+	statedb.SetBalance(address, new(big.Int).SetUint64(params.Ether))
+	blockchain := &failingStateChain{testBlockChain: newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))}
+
+	pool := New(testTxPoolConfig, blockchain)
+	if err := pool.Init(new(big.Int).SetUint64(testTxPoolConfig.PriceLimit), blockchain.CurrentBlock()); err != nil {
+		t.Fatalf("failed to init pool: %v", err)
+	}
+	defer pool.Close()
+
+	if err := pool.addRemoteSync(transaction(0, 100000, key)); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	// Reset the pool onto a head with unavailable state and ensure the pool is
+	// still functional
+	blockchain.fail.Store(true)
+	head := &types.Header{Number: big.NewInt(1), GasLimit: 1000000, BaseFee: big.NewInt(1), Root: common.Hash{0x01}}
+	<-pool.requestReset(nil, head)
+
+	if number := pool.currentHead.Load().Number.Uint64(); number != 0 {
+		t.Errorf("head number mismatch: have %d, want %d", number, 0)
+	}
+	if err := pool.addRemoteSync(transaction(1, 100000, key)); err != nil {
+		t.Fatalf("failed to add transaction after failed reset: %v", err)
+	}
+	if pending, _ := pool.Stats(); pending != 2 {
+		t.Errorf("pending transaction count mismatch: have %d, want %d", pending, 2)
+	}
+	// Ensure the next reset with available state goes through
+	blockchain.fail.Store(false)
+	<-pool.requestReset(nil, head)
+
+	if number := pool.currentHead.Load().Number.Uint64(); number != 1 {
+		t.Errorf("head number mismatch: have %d, want %d", number, 1)
+	}
+}
+
+// reorgChain is a test chain which serves blocks from a fixed set, allowing the
+// pool to walk both branches of a reorg.
+type reorgChain struct {
+	*testBlockChain
+	blocks map[common.Hash]*types.Block
+}
+
+func (c *reorgChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	if block := c.blocks[hash]; block != nil && block.NumberU64() == number {
+		return block
+	}
+	return nil
+}
+
+// Tests that transactions included in blocks dropped by a reorg are reinjected
+// into the pool, unless the new branch also includes them.
+func TestReorgReinjection(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key, _     = crypto.GenerateKey()
+		address    = crypto.PubkeyToAddress(key.PublicKey)
+		statedb, _ = state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		blockchain = &reorgChain{
+			testBlockChain: newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed)),
+			blocks:         make(map[common.Hash]*types.Block),
+		}
+	)
+	statedb.SetBalance(address, new(big.Int).SetUint64(params.Ether))
+
+	// Create two competing two-block branches on top of a common genesis
+	makeBlock := func(parent *types.Block, extra byte, txs ...*types.Transaction) *types.Block {
+		header := &types.Header{
+			Number:   new(big.Int),
+			GasLimit: 1000000,
+			BaseFee:  big.NewInt(1),
+			Extra:    []byte{extra},
+		}
+		if parent != nil {
+			header.ParentHash = parent.Hash()
+			header.Number.Add(parent.Number(), common.Big1)
+		}
+		block := types.NewBlock(header, txs, nil, nil, trie.NewStackTrie(nil))
+		blockchain.blocks[block.Hash()] = block
+		return block
+	}
+	var (
+		txs     = []*types.Transaction{transaction(0, 100000, key), transaction(1, 100000, key), transaction(2, 100000, key)}
+		genesis = makeBlock(nil, 0)
+		oldHead = makeBlock(makeBlock(genesis, 'a', txs[0], txs[1]), 'a', txs[2])
+		newHead = makeBlock(makeBlock(genesis, 'b', txs[0]), 'b')
+	)
+	pool := New(testTxPoolConfig, blockchain)
+	if err := pool.Init(new(big.Int).SetUint64(testTxPoolConfig.PriceLimit), oldHead.Header()); err != nil {
+		t.Fatalf("failed to init pool: %v", err)
+	}
+	defer pool.Close()
+
+	// Reorg onto the new branch, which only includes the first transaction
+	statedb.SetNonce(address, 1)
+	<-pool.requestReset(oldHead.Header(), newHead.Header())
+
+	pending, queued := pool.ContentFrom(address)
+	if len(pending) != 2 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", len(pending), 2)
+	}
+	for i, tx := range pending {
+		if want := txs[i+1].Hash(); tx.Hash() != want {
+			t.Errorf("pending transaction %d: hash mismatch: have %x, want %x", i, tx.Hash(), want)
+		}
+	}
+	if len(queued) != 0 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", len(queued), 0)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that remote transactions originating from accounts configured as local
+// are treated as local ones, exempting them from the pricing constraints, unless
+// local transaction handling is disabled altogether.
+func TestConfiguredLocals(t *testing.T)         { testConfiguredLocals(t, false) }
+func TestConfiguredLocalsNoLocals(t *testing.T) { testConfiguredLocals(t, true) }
+
+func testConfiguredLocals(t *testing.T, nolocals bool) {
+	t.Parallel()
+
+	var (
+		local, _   = crypto.GenerateKey()
+		remote, _  = crypto.GenerateKey()
+		statedb, _ = state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		blockchain = newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+	)
+	config := testTxPoolConfig
+	config.Locals = []common.Address{crypto.PubkeyToAddress(local.PublicKey)}
+	config.NoLocals = nolocals
+
+	pool := New(config, blockchain)
+	pool.Init(big.NewInt(1000), blockchain.CurrentBlock())
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(remote.PublicKey), big.NewInt(1000000000))
+
+	// With local handling disabled, configured locals should be treated as remotes
+	tx := pricedTransaction(0, 100000, big.NewInt(1), local)
+	if nolocals {
+		if err := pool.addRemoteSync(tx); !errors.Is(err, txpool.ErrUnderpriced) {
+			t.Errorf("configured local transaction error mismatch: have %v, want %v", err, txpool.ErrUnderpriced)
+		}
+		if pool.locals.contains(crypto.PubkeyToAddress(local.PublicKey)) {
+			t.Errorf("configured local tracked despite local handling being disabled")
+		}
+		return
+	}
+	// Underpriced transactions from configured locals should be accepted as locals
+	if err := pool.addRemoteSync(tx); err != nil {
+		t.Fatalf("failed to add transaction from configured local: %v", err)
+	}
+	if pool.all.GetLocal(tx.Hash()) == nil {
+		t.Errorf("transaction from configured local not tracked as local")
+	}
+	// Underpriced transactions from other accounts should be rejected, without
+	// needing the pool lock to do so
+	spam := pricedTransaction(0, 100000, big.NewInt(1), remote)
+
+	pool.mu.Lock()
+	err := pool.validateTxBasics(spam, false)
+	pool.mu.Unlock()
+	if !errors.Is(err, txpool.ErrUnderpriced) {
+		t.Errorf("remote transaction basic error mismatch: have %v, want %v", err, txpool.ErrUnderpriced)
+	}
+	if err := pool.addRemoteSync(spam); !errors.Is(err, txpool.ErrUnderpriced) {
+		t.Errorf("remote transaction error mismatch: have %v, want %v", err, txpool.ErrUnderpriced)
+	}
+}
+
+// Tests that the configured per-transaction gas ceiling is enforced below the
+// block gas limit.
+func TestMaxTxGas(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key, _     = crypto.GenerateKey()
+		statedb, _ = state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		blockchain = newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+	)
+	config := testTxPoolConfig
+	config.MaxTxGas = 500000
+
+	pool := New(config, blockchain)
+	pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock())
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	if err := pool.addRemoteSync(transaction(0, 500000, key)); err != nil {
+		t.Errorf("transaction at the gas ceiling rejected: %v", err)
+	}
+	if err := pool.addRemoteSync(transaction(1, 500001, key)); !errors.Is(err, ErrTxGasLimit) {
+		t.Errorf("transaction above the gas ceiling error mismatch: have %v, want %v", err, ErrTxGasLimit)
+	}
+	if err := pool.addLocal(transaction(1, 500001, key)); !errors.Is(err, ErrTxGasLimit) {
+		t.Errorf("local transaction above the gas ceiling error mismatch: have %v, want %v", err, ErrTxGasLimit)
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Errorf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+}
+
+// Tests that with fair eviction enabled, a full pool makes room for accounts
+// within their allowance by trimming accounts over theirs, instead of rejecting
+// new transactions priced like the cheapest ones already pooled.
+func TestFairEviction(t *testing.T)   { testFairEviction(t, true) }
+func TestUnfairEviction(t *testing.T) { testFairEviction(t, false) }
+
+func testFairEviction(t *testing.T, fair bool) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.AccountSlots = 2
+	config.AccountQueue = 2
+	config.GlobalSlots = 8
+	config.GlobalQueue = 2
+	config.FairEviction = fair
+
+	pool := New(config, blockchain)
+	pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock())
+	defer pool.Close()
+
+	keys := make([]*ecdsa.PrivateKey, 4)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000000))
+	}
+	// Fill the pool with a spammer holding more than its allowance at a slightly
+	// higher price, and two accounts within their allowance
+	var txs types.Transactions
+	for i := uint64(0); i < 7; i++ {
+		txs = append(txs, pricedTransaction(i, 100000, big.NewInt(2), keys[0]))
+	}
+	txs = append(txs, pricedTransaction(0, 100000, big.NewInt(1), keys[1]))
+	txs = append(txs, pricedTransaction(1, 100000, big.NewInt(1), keys[2]))
+	txs = append(txs, pricedTransaction(2, 100000, big.NewInt(1), keys[2]))
+
+	for i, err := range pool.addRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 8 || queued != 2 {
+		t.Fatalf("pool contents mismatch: have %d/%d, want %d/%d", pending, queued, 8, 2)
+	}
+	// A gapped transaction from a new account must not evict pending transactions
+	if fair {
+		err := pool.addRemoteSync(pricedTransaction(1, 100000, big.NewInt(1), keys[3]))
+		if !errors.Is(err, txpool.ErrUnderpriced) {
+			t.Fatalf("gapped transaction error mismatch: have %v, want %v", err, txpool.ErrUnderpriced)
+		}
+		if pending, queued := pool.Stats(); pending != 8 || queued != 2 {
+			t.Fatalf("pool contents mismatch after gapped transaction: have %d/%d, want %d/%d", pending, queued, 8, 2)
+		}
+		// Neither must an underpriced replacement, which is rejected anyway
+		if err := pool.addRemoteSync(pricedTransaction(0, 100001, big.NewInt(1), keys[1])); err == nil {
+			t.Fatalf("underpriced replacement accepted")
+		}
+		if pending, queued := pool.Stats(); pending != 8 || queued != 2 {
+			t.Fatalf("pool contents mismatch after replacement: have %d/%d, want %d/%d", pending, queued, 8, 2)
+		}
+	}
+	// Add a transaction from a new account, priced as the cheapest ones
+	err := pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(1), keys[3]))
+	if !fair {
+		if !errors.Is(err, txpool.ErrUnderpriced) {
+			t.Fatalf("error mismatch: have %v, want %v", err, txpool.ErrUnderpriced)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("failed to add transaction of account within allowance: %v", err)
+	}
+	// Ensure only the spammer's highest nonce transaction was evicted
+	pending, queued := pool.ContentFrom(crypto.PubkeyToAddress(keys[0].PublicKey))
+	if len(pending) != 6 || len(queued) != 0 || pending[5].Nonce() != 5 {
+		t.Errorf("spammer contents mismatch: have %d/%d, want %d/%d", len(pending), len(queued), 6, 0)
+	}
+	for i, key := range keys[1:] {
+		pending, queued := pool.ContentFrom(crypto.PubkeyToAddress(key.PublicKey))
+		if len(pending)+len(queued) == 0 {
+			t.Errorf("account %d: transactions evicted", i+1)
+		}
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that fair eviction picks the accounts over their allowance as of each
+// eviction, not as of an earlier one, when accounts cross their allowance in
+// between without a reorg running.
+func TestFairEvictionRecheck(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.AccountSlots = 4
+	config.AccountQueue = 2
+	config.GlobalSlots = 5
+	config.GlobalQueue = 1
+	config.FairEviction = true
+
+	pool := New(config, blockchain)
+	pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock())
+	defer pool.Close()
+
+	keys := make([]*ecdsa.PrivateKey, 5)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000000))
+	}
+	// Fill the pool with one account over its allowance, one at its allowance and
+	// a gapped transaction of a third one
+	txs := types.Transactions{
+		pricedTransaction(0, 100000, big.NewInt(1), keys[0]),
+		pricedTransaction(1, 100000, big.NewInt(1), keys[0]),
+		pricedTransaction(2, 100000, big.NewInt(1), keys[0]),
+		pricedTransaction(0, 100000, big.NewInt(1), keys[1]),
+		pricedTransaction(1, 100000, big.NewInt(1), keys[1]),
+		pricedTransaction(1, 100000, big.NewInt(1), keys[2]),
+	}
+	for i, err := range pool.addRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	// Run the whole sequence under the lock so no reorg happens in between
+	pool.mu.Lock()
+
+	// The first eviction trims the first account down to its allowance
+	if _, err := pool.add(pricedTransaction(0, 100000, big.NewInt(1), keys[3]), false); err != nil {
+		pool.mu.Unlock()
+		t.Fatalf("failed to add first transaction within allowance: %v", err)
+	}
+	// Drop the first account below its allowance and push the second one over
+	pool.removeTx(txs[1].Hash(), true)
+	if _, err := pool.add(pricedTransaction(2, 100000, big.NewInt(1), keys[1]), false); err != nil {
+		pool.mu.Unlock()
+		t.Fatalf("failed to grow second account: %v", err)
+	}
+	// The second eviction must trim the second account, not the first one
+	_, err := pool.add(pricedTransaction(0, 100000, big.NewInt(1), keys[4]), false)
+	pool.mu.Unlock()
+
+	if err != nil {
+		t.Fatalf("failed to add second transaction within allowance: %v", err)
+	}
+	addrs := make([]common.Address, len(keys))
+	for i, key := range keys {
+		addrs[i] = crypto.PubkeyToAddress(key.PublicKey)
+	}
+	<-pool.requestPromoteExecutables(newAccountSet(pool.signer, addrs...))
+
+	for i, want := range []int{1, 2, 1, 1, 1} {
+		pending, queued := pool.ContentFrom(addrs[i])
+		if have := len(pending) + len(queued); have != want {
+			t.Errorf("account %d: transaction count mismatch: have %d, want %d", i, have, want)
+		}
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 func testAddBalance(pool *LegacyPool, addr common.Address, amount *big.Int) {
 	pool.mu.Lock()
 	pool.currentState.AddBalance(addr, amount)
@@ -331,6 +800,64 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+// Tests that the intrinsic gas of contract creations is checked according to
+// the homestead rules of the chain config, not unconditionally.
+func TestContractCreationIntrinsicGas(t *testing.T) {
+	t.Parallel()
+
+	// The pool head is the genesis block, whose rules apply to all transactions
+	frontier := *params.TestChainConfig
+	frontier.HomesteadBlock = big.NewInt(1)
+
+	tests := []struct {
+		config *params.ChainConfig
+		err    error
+	}{
+		{&frontier, nil}, // Creations cost params.TxGas while the head is before homestead
+		{params.TestChainConfig, core.ErrIntrinsicGas}, // Creations cost params.TxGasContractCreation after homestead
+	}
+	for i, tt := range tests {
+		pool, key := setupPoolWithConfig(tt.config)
+		testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+
+		tx, _ := types.SignTx(types.NewContractCreation(0, big.NewInt(0), params.TxGas, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+		if err := pool.addRemote(tx); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+		pool.Close()
+	}
+}
+
+// Tests that contract creations are charged the EIP-3860 init code word gas
+// once Shanghai is active, rejecting ones which only cover the old costs.
+func TestInitCodeWordGas(t *testing.T) {
+	t.Parallel()
+
+	shanghai := *params.TestChainConfig
+	shanghai.ShanghaiTime = new(uint64)
+
+	initcode := make([]byte, 1024)
+	gas, _ := core.IntrinsicGas(initcode, nil, true, true, true, false)
+
+	tests := []struct {
+		config *params.ChainConfig
+		err    error
+	}{
+		{params.TestChainConfig, nil},     // Init code words are free before Shanghai
+		{&shanghai, core.ErrIntrinsicGas}, // Init code words cost params.InitCodeWordGas after Shanghai
+	}
+	for i, tt := range tests {
+		pool, key := setupPoolWithConfig(tt.config)
+		testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+		tx, _ := types.SignTx(types.NewContractCreation(0, big.NewInt(0), gas, big.NewInt(1), initcode), types.HomesteadSigner{}, key)
+		if err := pool.addRemote(tx); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+		pool.Close()
+	}
+}
+
 func TestQueue(t *testing.T) {
 	t.Parallel()
 
@@ -415,14 +942,48 @@ func TestTipAboveFeeCap(t *testing.T) {
 	}
 }
 
-func TestVeryHighValues(t *testing.T) {
+// Tests that dynamic fee transactions are only accepted once the pool's head
+// is past the London fork, and that the fork rules follow head resets.
+func TestDynamicFeeForkGating(t *testing.T) {
 	t.Parallel()
 
+	// Dynamic fee transactions should be accepted on a London genesis
 	pool, key := setupPoolWithConfig(eip1559Config)
 	defer pool.Close()
 
-	veryBigNumber := big.NewInt(1)
-	veryBigNumber.Lsh(veryBigNumber, 300)
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	if err := pool.addRemoteSync(dynamicFeeTx(0, 100000, big.NewInt(1), big.NewInt(1), key)); err != nil {
+		t.Fatalf("failed to add dynamic fee transaction on london chain: %v", err)
+	}
+	// Dynamic fee transactions should be rejected before London and accepted
+	// as soon as the pool is reset onto a London head
+	config := *eip1559Config
+	config.LondonBlock = big.NewInt(1)
+
+	pool, key = setupPoolWithConfig(&config)
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	tx := dynamicFeeTx(0, 100000, big.NewInt(1), big.NewInt(1), key)
+	if err := pool.addRemoteSync(tx); !errors.Is(err, core.ErrTxTypeNotSupported) {
+		t.Fatalf("pre-london dynamic fee error mismatch: have %v, want %v", err, core.ErrTxTypeNotSupported)
+	}
+	<-pool.requestReset(nil, &types.Header{Number: big.NewInt(1), GasLimit: 10000000, BaseFee: big.NewInt(1)})
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	if err := pool.addRemoteSync(tx); err != nil {
+		t.Fatalf("failed to add dynamic fee transaction after london: %v", err)
+	}
+}
+
+func TestVeryHighValues(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPoolWithConfig(eip1559Config)
+	defer pool.Close()
+
+	veryBigNumber := big.NewInt(1)
+	veryBigNumber.Lsh(veryBigNumber, 300)
 
 	tx := dynamicFeeTx(0, 100, big.NewInt(1), veryBigNumber, key)
 	if err := pool.addRemote(tx); err != core.ErrTipVeryHigh {
@@ -2389,6 +2950,79 @@ func testJournaling(t *testing.T, nolocals bool) {
 	pool.Close()
 }
 
+// Tests that journaled local transactions which became invalid while the node
+// was down are dropped on reload, keeping only the still valid ones.
+func TestJournalStaleLocals(t *testing.T) {
+	t.Parallel()
+
+	journal := filepath.Join(t.TempDir(), "transactions.rlp")
+
+	var (
+		local, _ = crypto.GenerateKey()
+		broke, _ = crypto.GenerateKey()
+		mined    = transaction(0, 100000, local)
+		valid    = transaction(1, 100000, local)
+		costly   = transaction(0, 100000, broke)
+	)
+	// Write the journal directly, as if left behind by a previous run
+	file, err := os.Create(journal)
+	if err != nil {
+		t.Fatalf("failed to create journal: %v", err)
+	}
+	for _, tx := range []*types.Transaction{mined, valid, costly} {
+		if err := rlp.Encode(file, tx); err != nil {
+			t.Fatalf("failed to write journal: %v", err)
+		}
+	}
+	file.Close()
+
+	// Start a pool on a state where the first transaction was already mined and
+	// the second sender has no funds
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetBalance(crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+	statedb.SetNonce(crypto.PubkeyToAddress(local.PublicKey), 1)
+	blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.Journal = journal
+	config.Rejournal = time.Minute
+
+	pool := New(config, blockchain)
+	pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock())
+	defer pool.Close()
+
+	if pool.Get(mined.Hash()) != nil {
+		t.Errorf("mined transaction reloaded")
+	}
+	if pool.Get(costly.Hash()) != nil {
+		t.Errorf("unaffordable transaction reloaded")
+	}
+	if pool.all.GetLocal(valid.Hash()) == nil {
+		t.Errorf("valid transaction not reloaded as local")
+	}
+	// Ensure the rotated journal only retained the valid transaction
+	input, err := os.Open(journal)
+	if err != nil {
+		t.Fatalf("failed to open rotated journal: %v", err)
+	}
+	defer input.Close()
+
+	var (
+		stream = rlp.NewStream(input, 0)
+		hashes []common.Hash
+	)
+	for {
+		tx := new(types.Transaction)
+		if err := stream.Decode(tx); err != nil {
+			break
+		}
+		hashes = append(hashes, tx.Hash())
+	}
+	if len(hashes) != 1 || hashes[0] != valid.Hash() {
+		t.Errorf("rotated journal mismatch: have %v, want [%v]", hashes, valid.Hash())
+	}
+}
+
 // TestStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestStatusCheck(t *testing.T) {
@@ -2444,6 +3078,355 @@ func TestStatusCheck(t *testing.T) {
 	}
 }
 
+// Tests that combined lookups return the transaction together with its status,
+// and report unknown hashes as missing.
+func TestLookup(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+
+	txs := []*types.Transaction{
+		transaction(0, 100000, key), // Pending
+		transaction(2, 100000, key), // Queued
+	}
+	for i, err := range pool.addRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	// Track a transaction in the lookup only, without it being in any list
+	stray := transaction(5, 100000, key)
+
+	pool.mu.Lock()
+	pool.all.Add(stray, false)
+	pool.mu.Unlock()
+
+	tests := []struct {
+		hash   common.Hash
+		tx     *types.Transaction
+		status txpool.TxStatus
+		ok     bool
+	}{
+		{txs[0].Hash(), txs[0], txpool.TxStatusPending, true},
+		{txs[1].Hash(), txs[1], txpool.TxStatusQueued, true},
+		{stray.Hash(), nil, txpool.TxStatusUnknown, false},
+		{common.Hash{0x01}, nil, txpool.TxStatusUnknown, false},
+	}
+	for i, tt := range tests {
+		tx, status, ok := pool.Lookup(tt.hash)
+		if ok != tt.ok {
+			t.Errorf("test %d: existence mismatch: have %v, want %v", i, ok, tt.ok)
+		}
+		if status != tt.status {
+			t.Errorf("test %d: status mismatch: have %v, want %v", i, status, tt.status)
+		}
+		if tx != tt.tx {
+			t.Errorf("test %d: transaction mismatch: have %v, want %v", i, tx, tt.tx)
+		}
+	}
+}
+
+// Tests that batch transaction retrievals return the results aligned with the
+// requested hashes, leaving gaps for unknown ones.
+func TestGetTransactions(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+
+	txs := []*types.Transaction{
+		transaction(0, 100000, key), // Pending
+		transaction(2, 100000, key), // Queued
+	}
+	pool.addRemotesSync(txs)
+
+	hashes := []common.Hash{{0x01}, txs[1].Hash(), {0x02}, txs[0].Hash()}
+	expect := []*types.Transaction{nil, txs[1], nil, txs[0]}
+
+	have := pool.GetTransactions(hashes)
+	if len(have) != len(expect) {
+		t.Fatalf("result count mismatch: have %d, want %d", len(have), len(expect))
+	}
+	for i, tx := range have {
+		if tx != expect[i] {
+			t.Errorf("transaction %d: mismatch: have %v, want %v", i, tx, expect[i])
+		}
+	}
+}
+
+// Tests that filtering the pending transactions cuts the account lists off at
+// the first rejected transaction, keeping the returned nonces contiguous.
+func TestPendingFiltered(t *testing.T) {
+	t.Parallel()
+
+	pool, _ := setupPool()
+	defer pool.Close()
+
+	keys := make([]*ecdsa.PrivateKey, 2)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000))
+	}
+	var (
+		txs    types.Transactions
+		banned = pricedTransaction(2, 100000, big.NewInt(2), keys[0])
+	)
+	for i := uint64(0); i < 4; i++ {
+		if i == 2 {
+			txs = append(txs, banned)
+		} else {
+			txs = append(txs, transaction(i, 100000, keys[0]))
+		}
+		txs = append(txs, transaction(i, 100000, keys[1]))
+	}
+	pool.addRemotesSync(txs)
+
+	pending := pool.PendingFiltered(false, func(tx *types.Transaction) bool {
+		return tx.Hash() != banned.Hash()
+	})
+	if have := len(pending[crypto.PubkeyToAddress(keys[0].PublicKey)]); have != 2 {
+		t.Errorf("filtered account transaction count mismatch: have %d, want %d", have, 2)
+	}
+	if have := len(pending[crypto.PubkeyToAddress(keys[1].PublicKey)]); have != 4 {
+		t.Errorf("unfiltered account transaction count mismatch: have %d, want %d", have, 4)
+	}
+	for addr, list := range pending {
+		for i, tx := range list {
+			if tx.Nonce() != uint64(i) {
+				t.Errorf("account %x: transaction %d: nonce mismatch: have %d, want %d", addr, i, tx.Nonce(), i)
+			}
+		}
+	}
+	// Ensure the filtering didn't mutate the pool
+	if pending, _ := pool.Stats(); pending != 8 {
+		t.Errorf("pending transaction count mismatch: have %d, want %d", pending, 8)
+	}
+}
+
+// Tests that filtered pending retrievals enforce the miner tip the same way as
+// unfiltered ones.
+func TestPendingFilteredEnforceTips(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	txs := types.Transactions{
+		dynamicFeeTx(0, 100000, big.NewInt(100), big.NewInt(5), key),
+		dynamicFeeTx(1, 100000, big.NewInt(3), big.NewInt(3), key), // Effective tip drops to 0 at base fee 3
+	}
+	for i, err := range pool.addRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	pool.mu.Lock()
+	pool.priced.SetBaseFee(big.NewInt(3))
+	pool.mu.Unlock()
+
+	all := func(*types.Transaction) bool { return true }
+	for _, enforce := range []bool{false, true} {
+		want := len(pool.Pending(enforce)[crypto.PubkeyToAddress(key.PublicKey)])
+		if have := len(pool.PendingFiltered(enforce, all)[crypto.PubkeyToAddress(key.PublicKey)]); have != want {
+			t.Errorf("enforce %v: transaction count mismatch: have %d, want %d", enforce, have, want)
+		}
+	}
+	if have := len(pool.PendingFiltered(true, all)[crypto.PubkeyToAddress(key.PublicKey)]); have != 1 {
+		t.Errorf("tip enforced transaction count mismatch: have %d, want %d", have, 1)
+	}
+}
+
+// Tests that the pending iterator yields transactions by price, while still
+// honouring the nonce ordering of each account.
+func TestPendingIterator(t *testing.T) {
+	t.Parallel()
+
+	pool, _ := setupPool()
+	defer pool.Close()
+
+	keys := make([]*ecdsa.PrivateKey, 5)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000000))
+	}
+	txs := types.Transactions{
+		pricedTransaction(0, 100000, big.NewInt(3), keys[0]),
+		pricedTransaction(0, 100000, big.NewInt(5), keys[1]),
+		pricedTransaction(0, 100000, big.NewInt(1), keys[2]),
+		pricedTransaction(0, 100000, big.NewInt(2), keys[3]),
+		pricedTransaction(0, 100000, big.NewInt(4), keys[4]),
+		pricedTransaction(1, 100000, big.NewInt(9), keys[4]), // Best price, but nonce gated
+	}
+	pool.addRemotesSync(txs)
+
+	next := pool.PendingIterator(false)
+	for i, want := range []*types.Transaction{txs[1], txs[4], txs[5]} {
+		tx, _, ok := next()
+		if !ok {
+			t.Fatalf("transaction %d: iterator exhausted", i)
+		}
+		if tx.Hash() != want.Hash() {
+			t.Errorf("transaction %d: mismatch: have price %v nonce %d, want price %v nonce %d", i, tx.GasPrice(), tx.Nonce(), want.GasPrice(), want.Nonce())
+		}
+	}
+	// Drain the iterator and ensure all transactions are yielded exactly once
+	count := 3
+	for _, _, ok := next(); ok; _, _, ok = next() {
+		count++
+	}
+	if count != len(txs) {
+		t.Errorf("iterated transaction count mismatch: have %d, want %d", count, len(txs))
+	}
+}
+
+// Tests that the pending iterator cuts an account's transactions off at the first
+// one paying too little tip, if tip enforcement is requested.
+func TestPendingIteratorEnforceTips(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	txs := types.Transactions{
+		dynamicFeeTx(0, 100000, big.NewInt(100), big.NewInt(5), key),
+		dynamicFeeTx(1, 100000, big.NewInt(3), big.NewInt(3), key), // Effective tip drops to 0 at base fee 3
+	}
+	for i, err := range pool.addRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	pool.mu.Lock()
+	pool.priced.SetBaseFee(big.NewInt(3))
+	pool.mu.Unlock()
+
+	for enforce, want := range map[bool]int{false: 2, true: 1} {
+		var have int
+		next := pool.PendingIterator(enforce)
+		for _, _, ok := next(); ok; _, _, ok = next() {
+			have++
+		}
+		if have != want {
+			t.Errorf("enforce %v: transaction count mismatch: have %d, want %d", enforce, have, want)
+		}
+	}
+}
+
+// Tests that the pending transactions retrieved for a block fit its gas limit,
+// are picked in price order and keep per-account nonce order without gaps.
+func TestPendingForBlock(t *testing.T) {
+	t.Parallel()
+
+	pool, _ := setupPool()
+	defer pool.Close()
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000000))
+	}
+	txs := types.Transactions{
+		pricedTransaction(0, 100000, big.NewInt(5), keys[0]),
+		pricedTransaction(1, 100000, big.NewInt(5), keys[0]),
+		pricedTransaction(2, 100000, big.NewInt(5), keys[0]),
+		pricedTransaction(0, 100000, big.NewInt(3), keys[1]),
+		pricedTransaction(1, 100000, big.NewInt(3), keys[1]),
+		pricedTransaction(0, 300000, big.NewInt(4), keys[2]), // Doesn't fit after the first account
+	}
+	for i, err := range pool.addRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	var (
+		limit   = uint64(450000)
+		pending = pool.PendingForBlock(false, limit)
+		gas     uint64
+	)
+	for addr, list := range pending {
+		for i, tx := range list {
+			if tx.Nonce() != uint64(i) {
+				t.Errorf("account %x: transaction %d: nonce mismatch: have %d, want %d", addr, i, tx.Nonce(), i)
+			}
+			gas += tx.Gas()
+		}
+	}
+	if gas > limit {
+		t.Errorf("cumulative gas exceeds limit: have %d, limit %d", gas, limit)
+	}
+	for i, want := range []int{3, 1, 0} {
+		if have := len(pending[crypto.PubkeyToAddress(keys[i].PublicKey)]); have != want {
+			t.Errorf("account %d: transaction count mismatch: have %d, want %d", i, have, want)
+		}
+	}
+	// Ensure a limit below the cheapest transaction yields nothing
+	if pending := pool.PendingForBlock(false, params.TxGas); len(pending) != 0 {
+		t.Errorf("pending set mismatch for tiny limit: have %d accounts, want 0", len(pending))
+	}
+}
+
+// Tests that the pool periodically samples its occupancy, retaining only the most
+// recent samples in the order they were taken.
+func TestStatsHistory(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.StatsInterval = time.Second
+	config.StatsHistory = 3
+
+	clock := new(mclock.Simulated)
+	pool := New(config, blockchain)
+	pool.clock = clock
+	pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock())
+	defer pool.Close()
+
+	key, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	// Grow the pool by one transaction between each sample, waiting for the sampler
+	// to (re)arm its timer to ensure the previous sample was already recorded
+	for i := uint64(0); i < 5; i++ {
+		clock.WaitForTimers(1)
+		if err := pool.addRemoteSync(transaction(i, 100000, key)); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+		clock.Run(time.Second)
+	}
+	// Wait for the last sample to be recorded and the sampler to rearm
+	clock.WaitForTimers(1)
+
+	samples := pool.StatsHistory()
+	if len(samples) != 3 {
+		t.Fatalf("sample count mismatch: have %d, want %d", len(samples), 3)
+	}
+	for i, sample := range samples {
+		want := StatsSample{
+			Time:    mclock.AbsTime(time.Duration(i+3) * time.Second),
+			Pending: i + 3,
+			Slots:   i + 3,
+		}
+		if sample != want {
+			t.Errorf("sample %d mismatch: have %+v, want %+v", i, sample, want)
+		}
+	}
+	// Ensure sampling is disabled by default
+	if samples := (&LegacyPool{}).StatsHistory(); samples != nil {
+		t.Errorf("disabled pool returned samples: %v", samples)
+	}
+}
+
 // Test the transaction slots consumption is computed correctly
 func TestSlotCount(t *testing.T) {
 	t.Parallel()
@@ -2455,6 +3438,16 @@ func TestSlotCount(t *testing.T) {
 	if slots := numSlots(smallTx); slots != 1 {
 		t.Fatalf("small transactions slot count mismatch: have %d want %d", slots, 1)
 	}
+	// Check that adding and removing it moves the lookup's slot count by one
+	all := newLookup()
+	all.Add(smallTx, false)
+	if slots := all.Slots(); slots != 1 {
+		t.Fatalf("lookup slot count mismatch after add: have %d want %d", slots, 1)
+	}
+	all.Remove(smallTx.Hash())
+	if slots := all.Slots(); slots != 0 {
+		t.Fatalf("lookup slot count mismatch after remove: have %d want %d", slots, 0)
+	}
 	// Check that a large transaction consumes the correct number of slots
 	bigTx := pricedDataTransaction(0, 0, big.NewInt(0), key, uint64(10*txSlotSize))
 	if slots := numSlots(bigTx); slots != 11 {
@@ -2462,6 +3455,479 @@ func TestSlotCount(t *testing.T) {
 	}
 }
 
+// Tests that the lookup's slot accounting stays correct when it is concurrently
+// modified and queried.
+func TestLookupConcurrentSlots(t *testing.T) {
+	t.Parallel()
+
+	var (
+		all     = newLookup()
+		key, _  = crypto.GenerateKey()
+		workers = 8
+		txs     = make([]types.Transactions, workers)
+		kept    int
+		wg      sync.WaitGroup
+	)
+	for i := range txs {
+		for j := 0; j < 50; j++ {
+			tx := pricedDataTransaction(uint64(i*50+j), 0, big.NewInt(1), key, uint64(j%3)*txSlotSize)
+			txs[i] = append(txs[i], tx)
+			if j%2 == 1 {
+				kept += numSlots(tx)
+			}
+		}
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(txs types.Transactions) {
+			defer wg.Done()
+			for j, tx := range txs {
+				all.Add(tx, j%4 == 0)
+				if all.Get(tx.Hash()) == nil {
+					t.Errorf("transaction %x missing after add", tx.Hash())
+				}
+				if all.Slots() <= 0 {
+					t.Errorf("slot count non-positive with transactions present")
+				}
+			}
+			// Remove every other transaction, keeping the rest
+			for j, tx := range txs {
+				if j%2 == 0 {
+					all.Remove(tx.Hash())
+				}
+				all.Slots()
+			}
+		}(txs[i])
+	}
+	wg.Wait()
+
+	if slots := all.Slots(); slots != kept {
+		t.Fatalf("slot count mismatch: have %d, want %d", slots, kept)
+	}
+	if count := all.Count(); count != workers*25 {
+		t.Fatalf("transaction count mismatch: have %d, want %d", count, workers*25)
+	}
+}
+
+// Tests that the slot usage reported by the pool matches the slots needed by
+// the contained transactions.
+func TestSlotStats(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	var (
+		txs   types.Transactions
+		slots int
+	)
+	for i, size := range []uint64{0, txSlotSize, 2 * txSlotSize, 3 * txSlotSize} {
+		tx := pricedDataTransaction(uint64(i), 2000000, big.NewInt(1), key, size)
+		txs = append(txs, tx)
+		slots += numSlots(tx)
+	}
+	if errs := pool.addRemotesSync(txs); errs[len(errs)-1] != nil {
+		t.Fatalf("failed to add transactions: %v", errs)
+	}
+	used, capacity := pool.SlotStats()
+	if used != slots {
+		t.Errorf("used slots mismatch: have %d, want %d", used, slots)
+	}
+	if want := testTxPoolConfig.GlobalSlots + testTxPoolConfig.GlobalQueue; capacity != want {
+		t.Errorf("slot capacity mismatch: have %d, want %d", capacity, want)
+	}
+}
+
+// Tests that block sized pending sets are retrievable through the aggregated pool.
+func TestTxPoolPendingForBlock(t *testing.T) {
+	t.Parallel()
+
+	txpool, pool, key := setupTxPool()
+	defer txpool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	pool.addRemotesSync([]*types.Transaction{transaction(0, 100000, key), transaction(1, 100000, key)})
+
+	have, want := txpool.PendingForBlock(false, 150000), pool.PendingForBlock(false, 150000)
+	if !reflect.DeepEqual(have, want) || len(have[crypto.PubkeyToAddress(key.PublicKey)]) != 1 {
+		t.Errorf("pending set mismatch: have %v, want %v", have, want)
+	}
+}
+
+// Tests that the pending iterator of the aggregated pool yields the transactions
+// of its subpools in price order, along with their tips.
+func TestTxPoolPendingIterator(t *testing.T) {
+	t.Parallel()
+
+	txpool, pool, _ := setupTxPool()
+	defer txpool.Close()
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	txs := make(types.Transactions, len(keys))
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000000))
+		txs[i] = pricedTransaction(0, 100000, big.NewInt(int64(i+1)), keys[i])
+	}
+	pool.addRemotesSync(txs)
+
+	next := txpool.PendingIterator(false)
+	for i := len(txs) - 1; i >= 0; i-- {
+		tx, tip, ok := next()
+		if !ok {
+			t.Fatalf("transaction %d: iterator exhausted", i)
+		}
+		if tx.Hash() != txs[i].Hash() {
+			t.Errorf("transaction %d: mismatch: have price %v, want %v", i, tx.GasPrice(), txs[i].GasPrice())
+		}
+		if tip.Cmp(tx.GasPrice()) != 0 {
+			t.Errorf("transaction %d: tip mismatch: have %v, want %v", i, tip, tx.GasPrice())
+		}
+	}
+	if _, _, ok := next(); ok {
+		t.Errorf("iterator not exhausted")
+	}
+}
+
+// Tests that transactions can be validated through the aggregated pool, with the
+// rejections classified the same way as on insertion.
+func TestTxPoolValidateTx(t *testing.T) {
+	t.Parallel()
+
+	pool, legacy, key := setupTxPool()
+	defer pool.Close()
+
+	testAddBalance(legacy, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+
+	if err := pool.ValidateTx(transaction(0, 100000, key), false); err != nil {
+		t.Errorf("valid transaction rejected: %v", err)
+	}
+	err := pool.ValidateTx(pricedTransaction(0, 100000, big.NewInt(0), key), false)
+	if !errors.Is(err, txpool.ErrUnderpriced) {
+		t.Errorf("underpriced transaction error mismatch: have %v, want %v", err, txpool.ErrUnderpriced)
+	}
+	var rerr *txpool.RejectionError
+	if !errors.As(err, &rerr) || !rerr.IsTemporary() {
+		t.Errorf("underpriced transaction rejection not classified as temporary: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Errorf("pool modified by validation: pending %d, queued %d", pending, queued)
+	}
+}
+
+// Tests that local transactions are retrievable through the aggregated pool.
+func TestTxPoolLocalTxs(t *testing.T) {
+	t.Parallel()
+
+	txpool, pool, key := setupTxPool()
+	defer txpool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	if err := pool.addLocal(transaction(0, 100000, key)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	have, want := txpool.LocalTxs(), pool.LocalTxs()
+	if len(have) != 1 || !reflect.DeepEqual(have, want) {
+		t.Errorf("local transactions mismatch: have %v, want %v", have, want)
+	}
+}
+
+// Tests that transactions and their status can be looked up through the
+// aggregated pool.
+func TestTxPoolLookup(t *testing.T) {
+	t.Parallel()
+
+	pool, legacy, key := setupTxPool()
+	defer pool.Close()
+
+	testAddBalance(legacy, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	txs := []*types.Transaction{transaction(0, 100000, key), transaction(2, 100000, key)}
+	legacy.addRemotesSync(txs)
+
+	for i, want := range []txpool.TxStatus{txpool.TxStatusPending, txpool.TxStatusQueued} {
+		tx, status, ok := pool.Lookup(txs[i].Hash())
+		if !ok || tx.Hash() != txs[i].Hash() || status != want {
+			t.Errorf("transaction %d: lookup mismatch: have %v/%v/%v, want %v/%v/%v", i, tx, status, ok, txs[i], want, true)
+		}
+	}
+	if _, status, ok := pool.Lookup(common.Hash{0x01}); ok || status != txpool.TxStatusUnknown {
+		t.Errorf("unknown transaction found: status %v", status)
+	}
+}
+
+// Tests that content snapshots are retrievable through the aggregated pool.
+func TestTxPoolSnapshot(t *testing.T) {
+	t.Parallel()
+
+	txpool, pool, key := setupTxPool()
+	defer txpool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	pool.addRemotesSync([]*types.Transaction{transaction(0, 100000, key), transaction(2, 100000, key)})
+
+	have, want := txpool.Snapshot(), pool.Snapshot()
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("snapshot mismatch: have %+v, want %+v", have, want)
+	}
+}
+
+// Tests that the per-account transaction counts are retrievable through the
+// aggregated pool.
+func TestTxPoolCountBySender(t *testing.T) {
+	t.Parallel()
+
+	txpool, pool, key := setupTxPool()
+	defer txpool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	pool.addRemotesSync([]*types.Transaction{transaction(0, 100000, key), transaction(2, 100000, key)})
+
+	have, want := txpool.CountBySender(), pool.CountBySender()
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("sender counts mismatch: have %v, want %v", have, want)
+	}
+}
+
+// Tests that the per-account transaction counts match the pool contents.
+func TestCountBySender(t *testing.T) {
+	t.Parallel()
+
+	pool, _ := setupPool()
+	defer pool.Close()
+
+	// Create accounts with only pending, only queued and mixed transactions
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000))
+	}
+	txs := types.Transactions{
+		transaction(0, 100000, keys[0]),
+		transaction(1, 100000, keys[0]),
+
+		transaction(1, 100000, keys[1]),
+		transaction(2, 100000, keys[1]),
+		transaction(3, 100000, keys[1]),
+
+		transaction(0, 100000, keys[2]),
+		transaction(2, 100000, keys[2]),
+	}
+	pool.addRemotesSync(txs)
+
+	counts := pool.CountBySender()
+	if len(counts) != len(keys) {
+		t.Fatalf("account count mismatch: have %d, want %d", len(counts), len(keys))
+	}
+	for i, want := range []txpool.SenderStats{{Pending: 2}, {Queued: 3}, {Pending: 1, Queued: 1}} {
+		if have := counts[crypto.PubkeyToAddress(keys[i].PublicKey)]; have != want {
+			t.Errorf("account %d: counts mismatch: have %+v, want %+v", i, have, want)
+		}
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the content of a single account is split into its executable and
+// gapped transactions.
+func TestContentFrom(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, addr, big.NewInt(1000000))
+
+	pool.addRemotesSync([]*types.Transaction{
+		transaction(0, 100000, key),
+		transaction(1, 100000, key),
+		transaction(3, 100000, key),
+		transaction(4, 100000, key),
+	})
+	nonces := func(txs []*types.Transaction) []uint64 {
+		var nonces []uint64
+		for _, tx := range txs {
+			nonces = append(nonces, tx.Nonce())
+		}
+		return nonces
+	}
+	pending, queued := pool.ContentFrom(addr)
+	if have, want := nonces(pending), []uint64{0, 1}; !reflect.DeepEqual(have, want) {
+		t.Errorf("pending nonces mismatch: have %v, want %v", have, want)
+	}
+	if have, want := nonces(queued), []uint64{3, 4}; !reflect.DeepEqual(have, want) {
+		t.Errorf("queued nonces mismatch: have %v, want %v", have, want)
+	}
+	// Ensure unknown accounts have no content at all
+	if pending, queued := pool.ContentFrom(common.Address{0x01}); pending != nil || queued != nil {
+		t.Errorf("unexpected content for unknown account: %v, %v", pending, queued)
+	}
+}
+
+// Tests that dumping an account lists each of its transactions with its status.
+func TestDumpSender(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, addr, big.NewInt(1000000))
+
+	pool.addRemotesSync([]*types.Transaction{
+		transaction(0, 100000, key),
+		transaction(1, 100000, key),
+		transaction(3, 100000, key),
+	})
+	dump := pool.DumpSender(addr)
+	for _, want := range []string{
+		"2 pending, 1 queued",
+		"nonce=0 status=pending",
+		"nonce=1 status=pending",
+		"nonce=3 status=queued",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("dump missing %q:\n%s", want, dump)
+		}
+	}
+	if dump := pool.DumpSender(common.Address{0x01}); !strings.Contains(dump, "0 pending, 0 queued") {
+		t.Errorf("unexpected dump for unknown account:\n%s", dump)
+	}
+}
+
+// Tests that transactions can be validated against the pool rules without being
+// inserted into the pool.
+func TestValidateTx(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, addr, big.NewInt(1000000))
+
+	if err := pool.ValidateTx(transaction(0, 100000, key), false); err != nil {
+		t.Errorf("valid transaction rejected: %v", err)
+	}
+	cheap := pricedTransaction(0, 100000, big.NewInt(0), key)
+	if err := pool.ValidateTx(cheap, false); !errors.Is(err, txpool.ErrUnderpriced) {
+		t.Errorf("underpriced remote transaction error mismatch: have %v, want %v", err, txpool.ErrUnderpriced)
+	}
+	if err := pool.ValidateTx(cheap, true); err != nil {
+		t.Errorf("underpriced local transaction rejected: %v", err)
+	}
+	if err := pool.ValidateTx(transaction(0, 100000000, key), false); !errors.Is(err, txpool.ErrGasLimit) {
+		t.Errorf("oversized transaction error mismatch: have %v, want %v", err, txpool.ErrGasLimit)
+	}
+	// Ensure none of the validations touched the pool
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Errorf("pool modified by validation: pending %d, queued %d", pending, queued)
+	}
+	if count := pool.all.Count(); count != 0 {
+		t.Errorf("lookup modified by validation: have %d transactions", count)
+	}
+	if nonce := pool.Nonce(addr); nonce != 0 {
+		t.Errorf("pending nonce modified by validation: have %d, want %d", nonce, 0)
+	}
+}
+
+// Tests that local transactions are retrievable in sender and nonce order, and
+// that remote ones are not included.
+func TestLocalTxs(t *testing.T) {
+	t.Parallel()
+
+	pool, _ := setupPool()
+	defer pool.Close()
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000))
+	}
+	// Insert the local transactions in reverse nonce order, with a gap
+	for _, key := range keys[:2] {
+		for _, nonce := range []uint64{3, 1, 0} {
+			if err := pool.addLocal(transaction(nonce, 100000, key)); err != nil {
+				t.Fatalf("failed to add local transaction: %v", err)
+			}
+		}
+	}
+	if err := pool.addRemoteSync(transaction(0, 100000, keys[2])); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	// Ensure the locals are returned sorted by sender, then by nonce
+	var (
+		txs   = pool.LocalTxs()
+		first = crypto.PubkeyToAddress(keys[0].PublicKey)
+	)
+	if second := crypto.PubkeyToAddress(keys[1].PublicKey); bytes.Compare(second[:], first[:]) < 0 {
+		first = second
+	}
+	if len(txs) != 6 {
+		t.Fatalf("local transaction count mismatch: have %d, want %d", len(txs), 6)
+	}
+	for i, tx := range txs {
+		from, _ := types.Sender(pool.signer, tx)
+		if (from == first) != (i < 3) {
+			t.Errorf("tx %d: sender order mismatch: have %x", i, from)
+		}
+		if want := []uint64{0, 1, 3}[i%3]; tx.Nonce() != want {
+			t.Errorf("tx %d: nonce mismatch: have %d, want %d", i, tx.Nonce(), want)
+		}
+	}
+}
+
+// Tests that pool snapshots are internally consistent even when taken while
+// transactions are concurrently being added.
+func TestSnapshotConsistency(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := uint64(0); i < 200; i++ {
+			// Interleave executable and gapped transactions
+			nonce := i
+			if i%4 == 3 {
+				nonce += 1000
+			}
+			pool.addRemote(transaction(nonce, 100000, key))
+		}
+	}()
+	count := func(txs map[common.Address][]*types.Transaction) int {
+		var n int
+		for _, list := range txs {
+			n += len(list)
+		}
+		return n
+	}
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		default:
+		}
+		snap := pool.Snapshot()
+		if have := count(snap.Pending); have != snap.PendingCount {
+			t.Fatalf("pending count mismatch: have %d, want %d", have, snap.PendingCount)
+		}
+		if have := count(snap.Queued); have != snap.QueuedCount {
+			t.Fatalf("queued count mismatch: have %d, want %d", have, snap.QueuedCount)
+		}
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Benchmarks the speed of validating the contents of the pending queue of the
 // transaction pool.
 func BenchmarkPendingDemotion100(b *testing.B)   { benchmarkPendingDemotion(b, 100) }
@@ -2547,6 +4013,50 @@ func benchmarkBatchInsert(b *testing.B, size int, local bool) {
 	}
 }
 
+// countingSigner is a signer wrapper counting the number of times the sender
+// of a transaction was recovered via an ECDSA signature verification.
+type countingSigner struct {
+	types.Signer
+	recoveries atomic.Int64
+}
+
+func (s *countingSigner) Sender(tx *types.Transaction) (common.Address, error) {
+	s.recoveries.Add(1)
+	return s.Signer.Sender(tx)
+}
+
+func (s *countingSigner) Equal(s2 types.Signer) bool {
+	return s2 == types.Signer(s)
+}
+
+// Benchmarks the number of sender recoveries needed to insert a batch of
+// transactions, which should be exactly one per transaction.
+func BenchmarkBatchInsertRecoveries(b *testing.B) {
+	pool, key := setupPool()
+	defer pool.Close()
+
+	signer := &countingSigner{Signer: pool.signer}
+	pool.signer, pool.locals.signer = signer, signer
+
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, account, big.NewInt(1000000000000000000))
+
+	batches := make([]types.Transactions, b.N)
+	for i := 0; i < b.N; i++ {
+		batches[i] = make(types.Transactions, 100)
+		for j := 0; j < 100; j++ {
+			batches[i][j] = transaction(uint64(100*i+j), 100000, key)
+		}
+	}
+	// Benchmark importing the transactions into the queue
+	b.ResetTimer()
+	for _, batch := range batches {
+		pool.addRemotes(batch)
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(signer.recoveries.Load())/float64(100*b.N), "recoveries/tx")
+}
+
 func BenchmarkInsertRemoteWithAllLocals(b *testing.B) {
 	// Allocate keys for testing
 	key, _ := crypto.GenerateKey()
@@ -2602,3 +4112,59 @@ func BenchmarkMultiAccountBatchInsert(b *testing.B) {
 		pool.addRemotesSync([]*types.Transaction{tx})
 	}
 }
+
+func BenchmarkAddRemotesPending1000x10(b *testing.B)   { benchmarkAddRemotesPending(b, 1000, 10) }
+func BenchmarkAddRemotesPending1000x100(b *testing.B)  { benchmarkAddRemotesPending(b, 1000, 100) }
+func BenchmarkAddRemotesPending10000x100(b *testing.B) { benchmarkAddRemotesPending(b, 10000, 100) }
+
+// Benchmarks filling an empty pool with a batch of executable transactions spread
+// across multiple accounts and retrieving them afterwards, reporting the time
+// spent inserting and retrieving separately.
+func benchmarkAddRemotesPending(b *testing.B, size int, accounts int) {
+	// Generate the transactions up front, signing is not what's being measured
+	keys := make([]*ecdsa.PrivateKey, accounts)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	txs := make([]*types.Transaction, size)
+	for i := range txs {
+		txs[i] = transaction(uint64(i/accounts), 100000, keys[i%accounts])
+	}
+	// Size the pool to fit the entire batch to avoid measuring evictions
+	config := testTxPoolConfig
+	config.GlobalSlots = uint64(size)
+
+	var add, pending time.Duration
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		for _, key := range keys {
+			statedb.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000000000000))
+		}
+		blockchain := newTestBlockChain(params.TestChainConfig, 10000000, statedb, new(event.Feed))
+
+		pool := New(config, blockchain)
+		pool.Init(new(big.Int).SetUint64(config.PriceLimit), blockchain.CurrentBlock())
+		b.StartTimer()
+
+		start := time.Now()
+		pool.addRemotesSync(txs)
+		add += time.Since(start)
+
+		start = time.Now()
+		pool.Pending(false)
+		pending += time.Since(start)
+
+		b.StopTimer()
+		if have, _ := pool.Stats(); have != size {
+			b.Fatalf("pending transaction count mismatch: have %d, want %d", have, size)
+		}
+		pool.Close()
+		b.StartTimer()
+	}
+	b.ReportMetric(float64(add.Nanoseconds())/float64(b.N), "add-ns/op")
+	b.ReportMetric(float64(pending.Nanoseconds())/float64(b.N), "pending-ns/op")
+}
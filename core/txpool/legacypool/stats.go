@@ -0,0 +1,68 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package legacypool
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+// StatsSample is a point-in-time record of the pool's occupancy.
+type StatsSample struct {
+	Time    mclock.AbsTime // Time the sample was taken at
+	Pending int            // Number of executable transactions
+	Queued  int            // Number of non-executable transactions
+	Slots   int            // Number of slots used by all transactions
+}
+
+// statsHistory is a fixed size ring buffer of the most recent occupancy samples.
+type statsHistory struct {
+	samples []StatsSample
+	next    int // Index the next sample is written to once the buffer is full
+	lock    sync.Mutex
+}
+
+// newStatsHistory creates a ring buffer retaining up to limit samples.
+func newStatsHistory(limit int) *statsHistory {
+	return &statsHistory{
+		samples: make([]StatsSample, 0, limit),
+	}
+}
+
+// add inserts a new sample, overwriting the oldest one if the buffer is full.
+func (h *statsHistory) add(sample StatsSample) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if len(h.samples) < cap(h.samples) {
+		h.samples = append(h.samples, sample)
+		return
+	}
+	h.samples[h.next] = sample
+	h.next = (h.next + 1) % len(h.samples)
+}
+
+// flatten returns a copy of the retained samples, oldest first.
+func (h *statsHistory) flatten() []StatsSample {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	samples := make([]StatsSample, 0, len(h.samples))
+	samples = append(samples, h.samples[h.next:]...)
+	return append(samples, h.samples[:h.next]...)
+}
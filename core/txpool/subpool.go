@@ -36,6 +36,11 @@ type Transaction struct {
 	BlobTxProofs  []kzg4844.Proof      // Proofs needed by the blob pool
 }
 
+// PendingIterator yields processable transactions one by one in price and nonce
+// order, along with the effective miner tip each pays at the next block's base
+// fee. Once exhausted, it returns false.
+type PendingIterator func() (tx *types.Transaction, tip *big.Int, ok bool)
+
 // SubPool represents a specialized transaction pool that lives on its own (e.g.
 // blob pool). Since independent of how many specialized pools we have, they do
 // need to be updated in lockstep and assemble into one coherent view for block
@@ -74,15 +79,37 @@ type SubPool interface {
 	// Get returns a transaction if it is contained in the pool, or nil otherwise.
 	Get(hash common.Hash) *Transaction
 
+	// GetTransactions returns the transactions matching the given hashes, aligned
+	// positionally with the input. Transactions not contained in the pool are nil.
+	GetTransactions(hashes []common.Hash) []*types.Transaction
+
 	// Add enqueues a batch of transactions into the pool if they are valid. Due
 	// to the large transaction churn, add may postpone fully integrating the tx
 	// to a later point to batch multiple ones together.
 	Add(txs []*Transaction, local bool, sync bool) []error
 
+	// ValidateTx checks whether a transaction would pass the subpool's validation
+	// rules against the current head and state, without adding it.
+	ValidateTx(tx *types.Transaction, local bool) error
+
 	// Pending retrieves all currently processable transactions, grouped by origin
 	// account and sorted by nonce.
 	Pending(enforceTips bool) map[common.Address][]*types.Transaction
 
+	// PendingFiltered retrieves all currently processable transactions accepted
+	// by the given filter, grouped by origin account and sorted by nonce. Each
+	// account's list is cut off at the first transaction rejected by the filter.
+	PendingFiltered(enforceTips bool, filter func(*types.Transaction) bool) map[common.Address][]*types.Transaction
+
+	// PendingForBlock retrieves the currently processable transactions that fit
+	// into a block with the given gas limit, grouped by origin account and sorted
+	// by nonce.
+	PendingForBlock(enforceTips bool, gasLimit uint64) map[common.Address][]*types.Transaction
+
+	// PendingIterator returns an iterator over all currently processable transactions,
+	// yielding them in price and nonce order.
+	PendingIterator(enforceTips bool) PendingIterator
+
 	// SubscribeTransactions subscribes to new transaction events.
 	SubscribeTransactions(ch chan<- core.NewTxsEvent) event.Subscription
 
@@ -98,6 +125,14 @@ type SubPool interface {
 	// pending as well as queued transactions, grouped by account and sorted by nonce.
 	Content() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction)
 
+	// Snapshot retrieves a consistent view of the subpool's pending and queued
+	// transactions along with the matching stats.
+	Snapshot() *Snapshot
+
+	// CountBySender retrieves the number of pending and queued transactions of
+	// each account in the subpool.
+	CountBySender() map[common.Address]SenderStats
+
 	// ContentFrom retrieves the data content of the transaction pool, returning the
 	// pending as well as queued transactions of this address, grouped by nonce.
 	ContentFrom(addr common.Address) ([]*types.Transaction, []*types.Transaction)
@@ -105,7 +140,14 @@ type SubPool interface {
 	// Locals retrieves the accounts currently considered local by the pool.
 	Locals() []common.Address
 
+	// LocalTxs retrieves all the transactions of local accounts currently in the
+	// subpool, sorted by sender address and then by nonce.
+	LocalTxs() types.Transactions
+
 	// Status returns the known status (unknown/pending/queued) of a transaction
 	// identified by their hashes.
 	Status(hash common.Hash) TxStatus
+
+	// Lookup retrieves a transaction along with its current status, atomically.
+	Lookup(hash common.Hash) (*types.Transaction, TxStatus, bool)
 }
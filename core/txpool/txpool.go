@@ -36,6 +36,23 @@ const (
 	TxStatusIncluded
 )
 
+// SenderStats is the number of pending and queued transactions of a single
+// account in the pool.
+type SenderStats struct {
+	Pending int // Number of executable transactions
+	Queued  int // Number of non-executable transactions
+}
+
+// Snapshot is a view of the pool contents and stats captured at a single point
+// in time, allowing callers to derive several responses from the same state.
+type Snapshot struct {
+	Pending map[common.Address][]*types.Transaction // Executable transactions, grouped by account and sorted by nonce
+	Queued  map[common.Address][]*types.Transaction // Non-executable transactions, grouped by account and sorted by nonce
+
+	PendingCount int // Total number of pending transactions
+	QueuedCount  int // Total number of queued transactions
+}
+
 // BlockChain defines the minimal set of methods needed to back a tx pool with
 // a chain. Exists to allow mocking the live chain out of tests.
 type BlockChain interface {
@@ -194,9 +211,26 @@ func (p *TxPool) Get(hash common.Hash) *Transaction {
 	return nil
 }
 
+// GetTransactions returns the transactions matching the given hashes, aligned
+// positionally with the input. Transactions not contained in the pool are nil.
+func (p *TxPool) GetTransactions(hashes []common.Hash) []*types.Transaction {
+	txs := make([]*types.Transaction, len(hashes))
+	for _, subpool := range p.subpools {
+		for i, tx := range subpool.GetTransactions(hashes) {
+			if tx != nil {
+				txs[i] = tx
+			}
+		}
+	}
+	return txs
+}
+
 // Add enqueues a batch of transactions into the pool if they are valid. Due
 // to the large transaction churn, add may postpone fully integrating the tx
 // to a later point to batch multiple ones together.
+//
+// Rejected transactions are reported via *RejectionError, classifying whether
+// the rejection is temporary or permanent.
 func (p *TxPool) Add(txs []*Transaction, local bool, sync bool) []error {
 	// Split the input transactions between the subpools. It shouldn't really
 	// happen that we receive merged batches, but better graceful than strange
@@ -230,16 +264,34 @@ func (p *TxPool) Add(txs []*Transaction, local bool, sync bool) []error {
 	for i, split := range splits {
 		// If the transaction was rejected by all subpools, mark it unsupported
 		if split == -1 {
-			errs[i] = core.ErrTxTypeNotSupported
+			errs[i] = NewRejectionError(core.ErrTxTypeNotSupported)
 			continue
 		}
 		// Find which subpool handled it and pull in the corresponding error
-		errs[i] = errsets[split][0]
+		if err := errsets[split][0]; err != nil {
+			errs[i] = NewRejectionError(err)
+		}
 		errsets[split] = errsets[split][1:]
 	}
 	return errs
 }
 
+// ValidateTx checks whether a transaction would pass the validation rules of the
+// subpool handling it, without adding it to the pool. It allows rejecting a
+// transaction early, e.g. when gating RPC submissions. The returned error is
+// classified the same way as the ones returned by Add.
+func (p *TxPool) ValidateTx(tx *types.Transaction, local bool) error {
+	for _, subpool := range p.subpools {
+		if subpool.Filter(tx) {
+			if err := subpool.ValidateTx(tx, local); err != nil {
+				return NewRejectionError(err)
+			}
+			return nil
+		}
+	}
+	return NewRejectionError(core.ErrTxTypeNotSupported)
+}
+
 // Pending retrieves all currently processable transactions, grouped by origin
 // account and sorted by nonce.
 func (p *TxPool) Pending(enforceTips bool) map[common.Address][]*types.Transaction {
@@ -252,6 +304,66 @@ func (p *TxPool) Pending(enforceTips bool) map[common.Address][]*types.Transacti
 	return txs
 }
 
+// PendingFiltered retrieves all currently processable transactions accepted by
+// the given filter, grouped by origin account and sorted by nonce. Each account's
+// list is cut off at the first transaction rejected by the filter, keeping the
+// nonces contiguous.
+func (p *TxPool) PendingFiltered(enforceTips bool, filter func(*types.Transaction) bool) map[common.Address][]*types.Transaction {
+	txs := make(map[common.Address][]*types.Transaction)
+	for _, subpool := range p.subpools {
+		for addr, set := range subpool.PendingFiltered(enforceTips, filter) {
+			txs[addr] = set
+		}
+	}
+	return txs
+}
+
+// PendingForBlock retrieves the currently processable transactions that fit into
+// a block with the given gas limit, grouped by origin account and sorted by nonce.
+// The subpools are filled in order, each getting the gas left over by the ones
+// before it.
+func (p *TxPool) PendingForBlock(enforceTips bool, gasLimit uint64) map[common.Address][]*types.Transaction {
+	picked := make(map[common.Address][]*types.Transaction)
+	for _, subpool := range p.subpools {
+		for addr, txs := range subpool.PendingForBlock(enforceTips, gasLimit) {
+			picked[addr] = txs
+			for _, tx := range txs {
+				gasLimit -= tx.Gas()
+			}
+		}
+	}
+	return picked
+}
+
+// PendingIterator returns an iterator over all currently processable transactions
+// across all subpools, yielding them in price and nonce order.
+func (p *TxPool) PendingIterator(enforceTips bool) PendingIterator {
+	var (
+		iters = make([]PendingIterator, len(p.subpools))
+		heads = make([]*types.Transaction, len(p.subpools))
+		tips  = make([]*big.Int, len(p.subpools))
+	)
+	for i, subpool := range p.subpools {
+		iters[i] = subpool.PendingIterator(enforceTips)
+		heads[i], tips[i], _ = iters[i]()
+	}
+	return func() (*types.Transaction, *big.Int, bool) {
+		// Pick the best paying transaction from the heads of the subpools
+		best := -1
+		for i, head := range heads {
+			if head != nil && (best == -1 || tips[i].Cmp(tips[best]) > 0) {
+				best = i
+			}
+		}
+		if best == -1 {
+			return nil, nil, false
+		}
+		tx, tip := heads[best], tips[best]
+		heads[best], tips[best], _ = iters[best]()
+		return tx, tip, true
+	}
+}
+
 // SubscribeNewTxsEvent registers a subscription of NewTxsEvent and starts sending
 // events to the given channel.
 func (p *TxPool) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
@@ -310,6 +422,43 @@ func (p *TxPool) Content() (map[common.Address][]*types.Transaction, map[common.
 	return runnable, blocked
 }
 
+// Snapshot retrieves a view of the pool's pending and queued transactions along
+// with the matching stats. Each subpool's part is captured consistently.
+func (p *TxPool) Snapshot() *Snapshot {
+	snap := &Snapshot{
+		Pending: make(map[common.Address][]*types.Transaction),
+		Queued:  make(map[common.Address][]*types.Transaction),
+	}
+	for _, subpool := range p.subpools {
+		sub := subpool.Snapshot()
+		for addr, txs := range sub.Pending {
+			snap.Pending[addr] = txs
+		}
+		for addr, txs := range sub.Queued {
+			snap.Queued[addr] = txs
+		}
+		snap.PendingCount += sub.PendingCount
+		snap.QueuedCount += sub.QueuedCount
+	}
+	return snap
+}
+
+// CountBySender retrieves the number of pending and queued transactions of each
+// account in the pool. It is a cheaper alternative to Content when only the
+// counts are needed.
+func (p *TxPool) CountBySender() map[common.Address]SenderStats {
+	counts := make(map[common.Address]SenderStats)
+	for _, subpool := range p.subpools {
+		for addr, stats := range subpool.CountBySender() {
+			total := counts[addr]
+			total.Pending += stats.Pending
+			total.Queued += stats.Queued
+			counts[addr] = total
+		}
+	}
+	return counts
+}
+
 // ContentFrom retrieves the data content of the transaction pool, returning the
 // pending as well as queued transactions of this address, grouped by nonce.
 func (p *TxPool) ContentFrom(addr common.Address) ([]*types.Transaction, []*types.Transaction) {
@@ -339,6 +488,16 @@ func (p *TxPool) Locals() []common.Address {
 	return flat
 }
 
+// LocalTxs retrieves all the transactions of local accounts currently in the
+// pool, e.g. to re-announce them to peers.
+func (p *TxPool) LocalTxs() types.Transactions {
+	var txs types.Transactions
+	for _, subpool := range p.subpools {
+		txs = append(txs, subpool.LocalTxs()...)
+	}
+	return txs
+}
+
 // Status returns the known status (unknown/pending/queued) of a transaction
 // identified by their hashes.
 func (p *TxPool) Status(hash common.Hash) TxStatus {
@@ -349,3 +508,14 @@ func (p *TxPool) Status(hash common.Hash) TxStatus {
 	}
 	return TxStatusUnknown
 }
+
+// Lookup retrieves a transaction along with its current status in a single pass,
+// avoiding the race of calling Get followed by Status.
+func (p *TxPool) Lookup(hash common.Hash) (*types.Transaction, TxStatus, bool) {
+	for _, subpool := range p.subpools {
+		if tx, status, ok := subpool.Lookup(hash); ok {
+			return tx, status, true
+		}
+	}
+	return nil, TxStatusUnknown, false
+}
@@ -0,0 +1,160 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// Tests that blob transactions are rejected if their blob fee cap does not cover
+// the blob fee of the block following the current head.
+func TestValidateBlobFeeCap(t *testing.T) {
+	t.Parallel()
+
+	var (
+		config = *params.TestChainConfig
+		key, _ = crypto.GenerateKey()
+	)
+	config.ShanghaiTime = new(uint64)
+	config.CancunTime = new(uint64)
+	signer := types.LatestSigner(&config)
+
+	// Create a head whose successor's blob fee is above the minimum
+	var (
+		excess = uint64(10 * params.BlobTxTargetDataGasPerBlock)
+		used   = uint64(params.BlobTxTargetDataGasPerBlock)
+		head   = &types.Header{
+			Number:        big.NewInt(1),
+			GasLimit:      30_000_000,
+			BaseFee:       big.NewInt(params.InitialBaseFee),
+			ExcessDataGas: &excess,
+			DataGasUsed:   &used,
+		}
+		blobfee = misc.CalcBlobFee(misc.CalcExcessDataGas(excess, used))
+	)
+	if blobfee.Cmp(big.NewInt(params.BlobTxMinDataGasprice)) <= 0 {
+		t.Fatalf("blob fee not above minimum: %v", blobfee)
+	}
+	// Create a valid blob sidecar to attach to the transactions
+	var blob kzg4844.Blob
+	commit, err := kzg4844.BlobToCommitment(blob)
+	if err != nil {
+		t.Fatalf("failed to create blob commitment: %v", err)
+	}
+	proof, err := kzg4844.ComputeBlobProof(blob, commit)
+	if err != nil {
+		t.Fatalf("failed to create blob proof: %v", err)
+	}
+	vhash := common.Hash(sha256.Sum256(commit[:]))
+	vhash[0] = params.BlobTxHashVersion
+
+	opts := &ValidationOptions{
+		Config:  &config,
+		Accept:  1 << types.BlobTxType,
+		MaxSize: 128 * 1024,
+		MinTip:  new(big.Int),
+	}
+	tests := []struct {
+		feecap *big.Int
+		err    error
+	}{
+		{new(big.Int).Sub(blobfee, common.Big1), ErrBlobFeeCapTooLow},
+		{blobfee, nil},
+		{new(big.Int).Add(blobfee, common.Big1), nil},
+	}
+	for i, tt := range tests {
+		tx := types.MustSignNewTx(key, signer, &types.BlobTx{
+			ChainID:    uint256.MustFromBig(config.ChainID),
+			GasTipCap:  uint256.NewInt(1),
+			GasFeeCap:  uint256.NewInt(params.InitialBaseFee),
+			Gas:        params.TxGas,
+			To:         common.Address{0x01},
+			Value:      new(uint256.Int),
+			BlobFeeCap: uint256.MustFromBig(tt.feecap),
+			BlobHashes: []common.Hash{vhash},
+		})
+		err := ValidateTransaction(tx, []kzg4844.Blob{blob}, []kzg4844.Commitment{commit}, []kzg4844.Proof{proof}, head, signer, opts)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// Tests that the balance check of blob transactions accounts for the blob gas
+// cost on top of the execution cost.
+func TestValidateBlobCost(t *testing.T) {
+	t.Parallel()
+
+	var (
+		config = *params.TestChainConfig
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+	)
+	config.ShanghaiTime = new(uint64)
+	config.CancunTime = new(uint64)
+	signer := types.LatestSigner(&config)
+
+	tx := types.MustSignNewTx(key, signer, &types.BlobTx{
+		ChainID:    uint256.MustFromBig(config.ChainID),
+		GasTipCap:  uint256.NewInt(1),
+		GasFeeCap:  uint256.NewInt(params.InitialBaseFee),
+		Gas:        params.TxGas,
+		To:         common.Address{0x01},
+		Value:      new(uint256.Int),
+		BlobFeeCap: uint256.NewInt(params.BlobTxMinDataGasprice),
+		BlobHashes: []common.Hash{{params.BlobTxHashVersion}},
+	})
+	var (
+		exec  = new(big.Int).Mul(big.NewInt(params.InitialBaseFee), new(big.Int).SetUint64(params.TxGas))
+		blobs = new(big.Int).Mul(big.NewInt(params.BlobTxMinDataGasprice), new(big.Int).SetUint64(params.BlobTxDataGasPerBlob))
+	)
+	tests := []struct {
+		balance *big.Int
+		err     error
+	}{
+		{exec, core.ErrInsufficientFunds}, // Covers execution, but not the blobs
+		{new(big.Int).Add(exec, new(big.Int).Sub(blobs, common.Big1)), core.ErrInsufficientFunds}, // Falls short by one wei
+		{new(big.Int).Add(exec, blobs), nil},                                                      // Covers both execution and the blobs
+	}
+	for i, tt := range tests {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.AddBalance(addr, tt.balance)
+
+		err := ValidateTransactionWithState(tx, signer, &ValidationOptionsWithState{
+			State:               statedb,
+			ExistingExpenditure: func(common.Address) *big.Int { return new(big.Int) },
+			ExistingCost:        func(common.Address, uint64) *big.Int { return nil },
+		})
+		if !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
@@ -18,9 +18,11 @@ package types
 
 import (
 	"bytes"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -35,6 +37,27 @@ type Withdrawal struct {
 	Amount    uint64         `json:"amount"`         // value of withdrawal in Gwei
 }
 
+// AmountWei returns the withdrawal amount converted from Gwei to Wei, the unit
+// of account balances.
+func (w *Withdrawal) AmountWei() *big.Int {
+	amount := new(big.Int).SetUint64(w.Amount)
+	return amount.Mul(amount, big.NewInt(params.GWei))
+}
+
+// Copy creates a deep copy of the withdrawal, safe to modify independently.
+func (w *Withdrawal) Copy() *Withdrawal {
+	cpy := *w
+	return &cpy
+}
+
+// Equal reports whether two withdrawals are identical in all fields.
+func (w *Withdrawal) Equal(other *Withdrawal) bool {
+	if w == nil || other == nil {
+		return w == other
+	}
+	return *w == *other
+}
+
 // field type overrides for gencodec
 type withdrawalMarshaling struct {
 	Index     hexutil.Uint64
@@ -0,0 +1,114 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Tests that withdrawal lists survive an RLP round trip, including empty ones.
+func TestWithdrawalsRLP(t *testing.T) {
+	tests := []Withdrawals{
+		{},
+		{{Index: 0, Validator: 0, Address: common.Address{}, Amount: 0}},
+		{
+			{Index: 1, Validator: 7, Address: common.Address{0x01}, Amount: 32_000_000_000},
+			{Index: 2, Validator: 1 << 40, Address: common.Address{0xff}, Amount: ^uint64(0)},
+		},
+	}
+	for i, want := range tests {
+		enc, err := rlp.EncodeToBytes(want)
+		if err != nil {
+			t.Fatalf("test %d: failed to encode withdrawals: %v", i, err)
+		}
+		var have Withdrawals
+		if err := rlp.DecodeBytes(enc, &have); err != nil {
+			t.Fatalf("test %d: failed to decode withdrawals: %v", i, err)
+		}
+		if have.Len() != want.Len() {
+			t.Fatalf("test %d: length mismatch: have %d, want %d", i, have.Len(), want.Len())
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("test %d: withdrawals mismatch: have %v, want %v", i, have, want)
+		}
+	}
+}
+
+// Tests that copied withdrawals are independent of the original.
+func TestWithdrawalCopy(t *testing.T) {
+	orig := &Withdrawal{Index: 1, Validator: 2, Address: common.Address{0x03}, Amount: 4}
+	cpy := orig.Copy()
+	if cpy == orig {
+		t.Fatal("copy aliases the original")
+	}
+	if !cpy.Equal(orig) {
+		t.Fatalf("copy mismatch: have %v, want %v", cpy, orig)
+	}
+	cpy.Index, cpy.Validator, cpy.Address, cpy.Amount = 5, 6, common.Address{0x07}, 8
+	if want := (Withdrawal{Index: 1, Validator: 2, Address: common.Address{0x03}, Amount: 4}); *orig != want {
+		t.Errorf("original modified through copy: have %v, want %v", orig, want)
+	}
+}
+
+// Tests that withdrawal equality checks every field.
+func TestWithdrawalEqual(t *testing.T) {
+	base := &Withdrawal{Index: 1, Validator: 2, Address: common.Address{0x03}, Amount: 4}
+
+	tests := []struct {
+		other *Withdrawal
+		equal bool
+	}{
+		{&Withdrawal{Index: 1, Validator: 2, Address: common.Address{0x03}, Amount: 4}, true},
+		{&Withdrawal{Index: 9, Validator: 2, Address: common.Address{0x03}, Amount: 4}, false},
+		{&Withdrawal{Index: 1, Validator: 9, Address: common.Address{0x03}, Amount: 4}, false},
+		{&Withdrawal{Index: 1, Validator: 2, Address: common.Address{0x09}, Amount: 4}, false},
+		{&Withdrawal{Index: 1, Validator: 2, Address: common.Address{0x03}, Amount: 9}, false},
+		{nil, false},
+	}
+	for i, tt := range tests {
+		if equal := base.Equal(tt.other); equal != tt.equal {
+			t.Errorf("test %d: equality mismatch: have %v, want %v", i, equal, tt.equal)
+		}
+	}
+	if !(*Withdrawal)(nil).Equal(nil) {
+		t.Errorf("nil withdrawals not equal")
+	}
+}
+
+// Tests that withdrawal amounts are converted from Gwei to Wei exactly.
+func TestWithdrawalAmountWei(t *testing.T) {
+	tests := []struct {
+		gwei uint64
+		wei  *big.Int
+	}{
+		{0, new(big.Int)},
+		{1, big.NewInt(1_000_000_000)},
+		{32_000_000_000, new(big.Int).Mul(big.NewInt(32), big.NewInt(params.Ether))},
+		{^uint64(0), new(big.Int).Mul(new(big.Int).SetUint64(^uint64(0)), big.NewInt(params.GWei))},
+	}
+	for i, tt := range tests {
+		if have := (&Withdrawal{Amount: tt.gwei}).AmountWei(); have.Cmp(tt.wei) != 0 {
+			t.Errorf("test %d: amount mismatch: have %v, want %v", i, have, tt.wei)
+		}
+	}
+}
@@ -327,7 +327,8 @@ func (b *EthAPIBackend) Stats() (runnable int, blocked int) {
 }
 
 func (b *EthAPIBackend) TxPoolContent() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction) {
-	return b.eth.txPool.Content()
+	snap := b.eth.txPool.Snapshot()
+	return snap.Pending, snap.Queued
 }
 
 func (b *EthAPIBackend) TxPoolContentFrom(addr common.Address) ([]*types.Transaction, []*types.Transaction) {
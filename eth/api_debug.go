@@ -24,6 +24,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -430,3 +431,48 @@ func (api *DebugAPI) SetTrieFlushInterval(interval string) error {
 func (api *DebugAPI) GetTrieFlushInterval() string {
 	return api.eth.blockchain.GetTrieFlushInterval().String()
 }
+
+// TxPoolSlotStats retrieves the number of transaction slots currently in use by
+// the transaction pool along with the total number of slots it may hold, giving
+// advance warning before it starts evicting transactions.
+func (api *DebugAPI) TxPoolSlotStats() map[string]hexutil.Uint64 {
+	used, capacity := api.eth.legacyPool.SlotStats()
+	return map[string]hexutil.Uint64{
+		"used":     hexutil.Uint64(used),
+		"capacity": hexutil.Uint64(capacity),
+	}
+}
+
+// TxPoolStatsSample is a transaction pool occupancy sample, as returned by
+// debug_txPoolStatsHistory.
+type TxPoolStatsSample struct {
+	Age     string         `json:"age"` // Time elapsed since the sample was taken
+	Pending hexutil.Uint64 `json:"pending"`
+	Queued  hexutil.Uint64 `json:"queued"`
+	Slots   hexutil.Uint64 `json:"slots"`
+}
+
+// TxPoolStatsHistory retrieves the recorded occupancy samples of the transaction
+// pool, oldest first. Sampling is disabled unless configured.
+func (api *DebugAPI) TxPoolStatsHistory() []TxPoolStatsSample {
+	var (
+		now     = mclock.Now()
+		samples = api.eth.legacyPool.StatsHistory()
+		result  = make([]TxPoolStatsSample, len(samples))
+	)
+	for i, sample := range samples {
+		result[i] = TxPoolStatsSample{
+			Age:     time.Duration(now - sample.Time).String(),
+			Pending: hexutil.Uint64(sample.Pending),
+			Queued:  hexutil.Uint64(sample.Queued),
+			Slots:   hexutil.Uint64(sample.Slots),
+		}
+	}
+	return result
+}
+
+// TxPoolDumpSender returns a human readable listing of the pending and queued
+// transactions of an account, along with its pool nonce.
+func (api *DebugAPI) TxPoolDumpSender(addr common.Address) string {
+	return api.eth.legacyPool.DumpSender(addr)
+}
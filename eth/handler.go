@@ -62,6 +62,10 @@ type txPool interface {
 	// tx hash.
 	Get(hash common.Hash) *txpool.Transaction
 
+	// GetTransactions retrieves the transactions from local txpool with the
+	// given hashes, aligned positionally with the input (nil if unknown).
+	GetTransactions(hashes []common.Hash) []*types.Transaction
+
 	// Add should add the given transactions to the pool.
 	Add(txs []*txpool.Transaction, local bool, sync bool) []error
 
@@ -69,6 +73,10 @@ type txPool interface {
 	// The slice should be modifiable by the caller.
 	Pending(enforceTips bool) map[common.Address][]*types.Transaction
 
+	// LocalTxs should return all transactions of local accounts, which are
+	// announced to new peers ahead of the remote ones.
+	LocalTxs() types.Transactions
+
 	// SubscribeNewTxsEvent should return an event subscription of
 	// NewTxsEvent and send events to the given channel.
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
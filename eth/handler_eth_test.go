@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"crypto/ecdsa"
 	"fmt"
 	"math/big"
 	"testing"
@@ -31,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/event"
@@ -382,6 +384,78 @@ func testSendTransactions(t *testing.T, protocol uint) {
 	}
 }
 
+// Tests that local transactions are announced to new peers ahead of the remote
+// ones, and that queued local transactions are not announced at all.
+func TestSendLocalTransactions(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestHandler()
+	defer handler.close()
+
+	localKey, _ := crypto.GenerateKey()
+	sign := func(nonce uint64, key *ecdsa.PrivateKey) *types.Transaction {
+		tx := types.NewTransaction(nonce, common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil)
+		tx, _ = types.SignTx(tx, types.HomesteadSigner{}, key)
+		return tx
+	}
+	var (
+		remote = sign(0, testKey)
+		local  = sign(0, localKey)
+		queued = sign(2, localKey) // nonce gap, not in the pending set
+	)
+	// Fill the pool directly to avoid racing the broadcast of new transactions
+	handler.txpool.lock.Lock()
+	handler.txpool.pool[remote.Hash()] = remote
+	handler.txpool.pool[local.Hash()] = local
+	handler.txpool.locals = types.Transactions{local, queued}
+	handler.txpool.lock.Unlock()
+
+	// Create a source handler to send messages through and a sink peer to receive them
+	p2pSrc, p2pSink := p2p.MsgPipe()
+	defer p2pSrc.Close()
+	defer p2pSink.Close()
+
+	src := eth.NewPeer(eth.ETH68, p2p.NewPeerPipe(enode.ID{1}, "", nil, p2pSrc), p2pSrc, handler.txpool)
+	sink := eth.NewPeer(eth.ETH68, p2p.NewPeerPipe(enode.ID{2}, "", nil, p2pSink), p2pSink, handler.txpool)
+	defer src.Close()
+	defer sink.Close()
+
+	go handler.handler.runEthPeer(src, func(peer *eth.Peer) error {
+		return eth.Handle((*ethHandler)(handler.handler), peer)
+	})
+	// Run the handshake locally to avoid spinning up a source handler
+	var (
+		genesis = handler.chain.Genesis()
+		head    = handler.chain.CurrentBlock()
+		td      = handler.chain.GetTd(head.Hash(), head.Number.Uint64())
+	)
+	if err := sink.Handshake(1, td, head.Hash(), genesis.Hash(), forkid.NewIDWithChain(handler.chain), forkid.NewFilter(handler.chain)); err != nil {
+		t.Fatalf("failed to run protocol handshake")
+	}
+	backend := new(testEthHandler)
+
+	anns := make(chan []common.Hash)
+	annSub := backend.txAnnounces.Subscribe(anns)
+	defer annSub.Unsubscribe()
+
+	go eth.Handle(backend, sink)
+
+	select {
+	case hashes := <-anns:
+		want := []common.Hash{local.Hash(), remote.Hash()}
+		if len(hashes) != len(want) {
+			t.Fatalf("announced transaction count mismatch: have %d, want %d", len(hashes), len(want))
+		}
+		for i, hash := range hashes {
+			if hash != want[i] {
+				t.Errorf("announcement %d: hash mismatch: have %x, want %x", i, hash, want[i])
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("initial transaction announcement timeout")
+	}
+}
+
 // Tests that transactions get propagated to all attached peers, either via direct
 // broadcasts or via announcements/retrievals.
 func TestTransactionPropagation66(t *testing.T) { testTransactionPropagation(t, eth.ETH66) }
@@ -48,7 +48,8 @@ var (
 // Its goal is to get around setting up a valid statedb for the balance and nonce
 // checks.
 type testTxPool struct {
-	pool map[common.Hash]*types.Transaction // Hash map of collected transactions
+	pool   map[common.Hash]*types.Transaction // Hash map of collected transactions
+	locals types.Transactions                 // Transactions reported as local, pending or not
 
 	txFeed event.Feed   // Notification feed to allow waiting for inclusion
 	lock   sync.RWMutex // Protects the transaction pool
@@ -82,6 +83,19 @@ func (p *testTxPool) Get(hash common.Hash) *txpool.Transaction {
 	return nil
 }
 
+// GetTransactions retrieves the transactions from local txpool with given
+// tx hashes, leaving nil gaps for unknown ones.
+func (p *testTxPool) GetTransactions(hashes []common.Hash) []*types.Transaction {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	txs := make([]*types.Transaction, len(hashes))
+	for i, hash := range hashes {
+		txs[i] = p.pool[hash]
+	}
+	return txs
+}
+
 // Add appends a batch of transactions to the pool, and notifies any
 // listeners if the addition channel is non nil
 func (p *testTxPool) Add(txs []*txpool.Transaction, local bool, sync bool) []error {
@@ -116,6 +130,15 @@ func (p *testTxPool) Pending(enforceTips bool) map[common.Address][]*types.Trans
 	return batches
 }
 
+// LocalTxs returns the transactions configured as local, which need not be in
+// the pending set.
+func (p *testTxPool) LocalTxs() types.Transactions {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.locals
+}
+
 // SubscribeNewTxsEvent should return an event subscription of NewTxsEvent and
 // send events to the given channel.
 func (p *testTxPool) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
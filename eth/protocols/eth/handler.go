@@ -24,6 +24,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
@@ -91,6 +92,10 @@ type Backend interface {
 type TxPool interface {
 	// Get retrieves the transaction from the local txpool with the given hash.
 	Get(hash common.Hash) *txpool.Transaction
+
+	// GetTransactions retrieves the transactions from the local txpool with the
+	// given hashes, aligned positionally with the input (nil if unknown).
+	GetTransactions(hashes []common.Hash) []*types.Transaction
 }
 
 // MakeProtocols constructs the P2P protocol definitions for `eth`.
@@ -38,6 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 var (
@@ -624,3 +625,48 @@ func testGetBlockReceipts(t *testing.T, protocol uint) {
 		t.Errorf("receipts mismatch: %v", err)
 	}
 }
+
+// Tests that pooled transaction retrievals answer with the known transactions
+// in request order, skipping the unknown ones.
+func TestGetPooledTransactions(t *testing.T) {
+	t.Parallel()
+
+	backend := newTestBackend(0)
+	defer backend.close()
+
+	signer := types.LatestSigner(backend.chain.Config())
+	txs := make([]*txpool.Transaction, 2)
+	for i := range txs {
+		tx := types.MustSignNewTx(testKey, signer, &types.LegacyTx{
+			Nonce:    uint64(i),
+			To:       &common.Address{0x01},
+			Gas:      params.TxGas,
+			GasPrice: big.NewInt(params.InitialBaseFee),
+		})
+		txs[i] = &txpool.Transaction{Tx: tx}
+	}
+	for i, err := range backend.txpool.Add(txs, true, true) {
+		if err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	query := GetPooledTransactionsPacket{{0x01}, txs[1].Tx.Hash(), {0x02}, txs[0].Tx.Hash()}
+	hashes, encoded := answerGetPooledTransactions(backend, query, nil)
+
+	want := []common.Hash{txs[1].Tx.Hash(), txs[0].Tx.Hash()}
+	if len(hashes) != len(want) || len(encoded) != len(want) {
+		t.Fatalf("response length mismatch: have %d/%d, want %d", len(hashes), len(encoded), len(want))
+	}
+	for i, hash := range hashes {
+		if hash != want[i] {
+			t.Errorf("transaction %d: hash mismatch: have %x, want %x", i, hash, want[i])
+		}
+		var tx types.Transaction
+		if err := rlp.DecodeBytes(encoded[i], &tx); err != nil {
+			t.Fatalf("transaction %d: failed to decode: %v", i, err)
+		}
+		if tx.Hash() != want[i] {
+			t.Errorf("transaction %d: encoded hash mismatch: have %x, want %x", i, tx.Hash(), want[i])
+		}
+	}
+}
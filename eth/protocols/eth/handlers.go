@@ -488,17 +488,18 @@ func answerGetPooledTransactions(backend Backend, query GetPooledTransactionsPac
 		hashes []common.Hash
 		txs    []rlp.RawValue
 	)
-	for _, hash := range query {
+	// Retrieve all the requested transactions in one go, skipping the unknown ones
+	for i, tx := range backend.TxPool().GetTransactions(query) {
 		if bytes >= softResponseLimit {
 			break
 		}
-		// Retrieve the requested transaction, skipping if unknown to us
-		tx := backend.TxPool().Get(hash)
 		if tx == nil {
 			continue
 		}
+		hash := query[i]
+
 		// If known, encode and queue for response packet
-		if encoded, err := rlp.EncodeToBytes(tx.Tx); err != nil {
+		if encoded, err := rlp.EncodeToBytes(tx); err != nil {
 			log.Error("Failed to encode transaction", "err", err)
 		} else {
 			hashes = append(hashes, hash)
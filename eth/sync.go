@@ -42,11 +42,26 @@ func (h *handler) syncTransactions(p *eth.Peer) {
 	// order, insertions could overflow the non-executable queues and get dropped.
 	//
 	// TODO(karalabe): Figure out if we could get away with random order somehow
-	var txs types.Transactions
-	pending := h.txpool.Pending(false)
-	for _, batch := range pending {
-		txs = append(txs, batch...)
+	//
+	// Local transactions go first: the node is the one responsible for getting
+	// them out, and they might have been dropped by the network since they were
+	// first announced, e.g. while the node was offline. Only the pending ones
+	// are announced though, queued locals would arrive with nonce gaps.
+	local := make(map[common.Hash]struct{})
+	for _, tx := range h.txpool.LocalTxs() {
+		local[tx.Hash()] = struct{}{}
+	}
+	var txs, remotes types.Transactions
+	for _, batch := range h.txpool.Pending(false) {
+		for _, tx := range batch {
+			if _, ok := local[tx.Hash()]; ok {
+				txs = append(txs, tx)
+			} else {
+				remotes = append(remotes, tx)
+			}
+		}
 	}
+	txs = append(txs, remotes...)
 	if len(txs) == 0 {
 		return
 	}
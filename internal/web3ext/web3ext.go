@@ -447,6 +447,22 @@ web3._extend({
 			call: 'debug_getBadBlocks',
 			params: 0,
 		}),
+		new web3._extend.Method({
+			name: 'txPoolSlotStats',
+			call: 'debug_txPoolSlotStats',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'txPoolStatsHistory',
+			call: 'debug_txPoolStatsHistory',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'txPoolDumpSender',
+			call: 'debug_txPoolDumpSender',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter],
+		}),
 		new web3._extend.Method({
 			name: 'storageRangeAt',
 			call: 'debug_storageRangeAt',
@@ -144,3 +144,58 @@ func TestTxPool(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the fork indicators are set up on pool creation, accepting dynamic
+// fee transactions only if London is active for the next pending block.
+func TestTxPoolDynamicFeeGating(t *testing.T) {
+	prelondon := *params.TestChainConfig
+	prelondon.LondonBlock = big.NewInt(10)
+	prelondon.ArrowGlacierBlock = big.NewInt(10)
+	prelondon.GrayGlacierBlock = big.NewInt(10)
+
+	testTxPoolDynamicFeeGating(t, params.TestChainConfig, nil)
+	testTxPoolDynamicFeeGating(t, &prelondon, core.ErrTxTypeNotSupported)
+}
+
+func testTxPoolDynamicFeeGating(t *testing.T, config *params.ChainConfig, want error) {
+	var (
+		sdb   = rawdb.NewMemoryDatabase()
+		ldb   = rawdb.NewMemoryDatabase()
+		gspec = &core.Genesis{
+			Config: config,
+			Alloc:  core.GenesisAlloc{testBankAddress: {Balance: testBankFunds}},
+		}
+	)
+	if config.IsLondon(common.Big0) {
+		gspec.BaseFee = big.NewInt(params.InitialBaseFee)
+	}
+	blockchain, _ := core.NewBlockChain(sdb, nil, gspec, nil, ethash.NewFullFaker(), vm.Config{}, nil, nil)
+	defer blockchain.Stop()
+
+	gspec.MustCommit(ldb)
+	odr := &testOdr{sdb: sdb, ldb: ldb, serverState: blockchain.StateCache(), indexerConfig: TestClientIndexerConfig}
+	relay := &testTxRelay{
+		send:    make(chan int, 1),
+		discard: make(chan int, 1),
+		mined:   make(chan int, 1),
+	}
+	lightchain, _ := NewLightChain(odr, config, ethash.NewFullFaker())
+	pool := NewTxPool(config, lightchain, relay)
+	defer pool.Stop()
+
+	tx, _ := types.SignNewTx(testBankKey, types.LatestSigner(config), &types.DynamicFeeTx{
+		ChainID:   config.ChainID,
+		Nonce:     0,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(params.InitialBaseFee),
+		Gas:       params.TxGas,
+		To:        &acc1Addr,
+		Value:     big.NewInt(10000),
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := pool.Add(ctx, tx); err != want {
+		t.Fatalf("error mismatch: have %v, want %v", err, want)
+	}
+}
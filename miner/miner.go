@@ -40,9 +40,27 @@ import (
 // to offer all the functions here.
 type Backend interface {
 	BlockChain() *core.BlockChain
-	TxPool() *txpool.TxPool
+	TxPool() TxPool
 }
 
+// TxPool defines the methods of the transaction pool needed by the miner to
+// assemble blocks. It is satisfied by *txpool.TxPool, but allows mocking the
+// pool out in tests.
+type TxPool interface {
+	// Pending retrieves all currently processable transactions, grouped by origin
+	// account and sorted by nonce.
+	Pending(enforceTips bool) map[common.Address][]*types.Transaction
+
+	// Locals retrieves the accounts currently considered local by the pool.
+	Locals() []common.Address
+
+	// SubscribeNewTxsEvent subscribes to new transaction events.
+	SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription
+}
+
+// Ensure the concrete pool satisfies the miner's requirements.
+var _ TxPool = (*txpool.TxPool)(nil)
+
 // Config is the configuration parameters of mining.
 type Config struct {
 	Etherbase common.Address `toml:",omitempty"` // Public address for block mining rewards
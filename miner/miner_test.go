@@ -54,7 +54,7 @@ func (m *mockBackend) BlockChain() *core.BlockChain {
 	return m.bc
 }
 
-func (m *mockBackend) TxPool() *txpool.TxPool {
+func (m *mockBackend) TxPool() TxPool {
 	return m.txPool
 }
 
@@ -763,6 +763,11 @@ func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByP
 			log.Trace("Not enough gas for further transactions", "have", env.gasPool, "want", params.TxGas)
 			break
 		}
+		// If the block can't hold any further transactions then we're done.
+		if max := w.chainConfig.MaxTxPerBlock; max > 0 && uint64(env.tcount) >= max {
+			log.Trace("Not enough room for further transactions", "have", env.tcount, "max", max)
+			break
+		}
 		// Retrieve the next transaction and abort if all done.
 		tx := txs.Peek()
 		if tx == nil {
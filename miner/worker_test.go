@@ -145,7 +145,7 @@ func newTestWorkerBackend(t *testing.T, chainConfig *params.ChainConfig, engine
 }
 
 func (b *testWorkerBackend) BlockChain() *core.BlockChain { return b.chain }
-func (b *testWorkerBackend) TxPool() *txpool.TxPool       { return b.txPool }
+func (b *testWorkerBackend) TxPool() TxPool               { return b.txPool }
 
 func (b *testWorkerBackend) newRandomTx(creation bool) *types.Transaction {
 	var tx *types.Transaction
@@ -481,3 +481,96 @@ func testGetSealingWork(t *testing.T, chainConfig *params.ChainConfig, engine co
 		}
 	}
 }
+
+// mockTxPool is a transaction pool serving a fixed set of pending transactions,
+// allowing blocks to be assembled without a real pool.
+type mockTxPool struct {
+	pending map[common.Address][]*types.Transaction
+	feed    event.Feed
+}
+
+func (p *mockTxPool) Pending(enforceTips bool) map[common.Address][]*types.Transaction {
+	// The miner consumes the returned map, hand out a copy
+	pending := make(map[common.Address][]*types.Transaction, len(p.pending))
+	for addr, txs := range p.pending {
+		pending[addr] = append([]*types.Transaction(nil), txs...)
+	}
+	return pending
+}
+
+func (p *mockTxPool) Locals() []common.Address { return nil }
+
+func (p *mockTxPool) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
+	return p.feed.Subscribe(ch)
+}
+
+// mockPoolBackend is a worker backend with a mocked out transaction pool.
+type mockPoolBackend struct {
+	*testWorkerBackend
+	pool *mockTxPool
+}
+
+func (b *mockPoolBackend) TxPool() TxPool { return b.pool }
+
+// Tests that the worker can assemble blocks from a mocked transaction pool.
+func TestGetSealingWorkMockTxPool(t *testing.T) {
+	var (
+		engine  = ethash.NewFaker()
+		backend = &mockPoolBackend{
+			testWorkerBackend: newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0),
+			pool: &mockTxPool{pending: map[common.Address][]*types.Transaction{
+				testBankAddress: {pendingTxs[0].Tx, newTxs[0]},
+			}},
+		}
+	)
+	defer engine.Close()
+
+	w := newWorker(testConfig, ethashChainConfig, engine, backend, new(event.TypeMux), nil, false)
+	defer w.close()
+
+	block, _, err := w.getSealingBlock(backend.chain.CurrentBlock().Hash(), uint64(time.Now().Unix()), testUserAddress, common.Hash{}, nil, false)
+	if err != nil {
+		t.Fatalf("failed to generate block: %v", err)
+	}
+	want := backend.pool.pending[testBankAddress]
+	if len(block.Transactions()) != len(want) {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(block.Transactions()), len(want))
+	}
+	for i, tx := range block.Transactions() {
+		if tx.Hash() != want[i].Hash() {
+			t.Errorf("transaction %d: hash mismatch: have %x, want %x", i, tx.Hash(), want[i].Hash())
+		}
+	}
+}
+
+// Tests that the worker stops filling a block once it holds as many transactions
+// as the chain config allows.
+func TestGetSealingWorkMaxTxPerBlock(t *testing.T) {
+	config := *ethashChainConfig
+	config.MaxTxPerBlock = 1
+
+	var (
+		engine  = ethash.NewFaker()
+		backend = &mockPoolBackend{
+			testWorkerBackend: newTestWorkerBackend(t, &config, engine, rawdb.NewMemoryDatabase(), 0),
+			pool: &mockTxPool{pending: map[common.Address][]*types.Transaction{
+				testBankAddress: {pendingTxs[0].Tx, newTxs[0]},
+			}},
+		}
+	)
+	defer engine.Close()
+
+	w := newWorker(testConfig, &config, engine, backend, new(event.TypeMux), nil, false)
+	defer w.close()
+
+	block, _, err := w.getSealingBlock(backend.chain.CurrentBlock().Hash(), uint64(time.Now().Unix()), testUserAddress, common.Hash{}, nil, false)
+	if err != nil {
+		t.Fatalf("failed to generate block: %v", err)
+	}
+	if len(block.Transactions()) != 1 {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(block.Transactions()), 1)
+	}
+	if have, want := block.Transactions()[0].Hash(), pendingTxs[0].Tx.Hash(); have != want {
+		t.Errorf("transaction hash mismatch: have %x, want %x", have, want)
+	}
+}
@@ -274,6 +274,15 @@ type ChainConfig struct {
 	// even without having seen the TTD locally (safer long term).
 	TerminalTotalDifficultyPassed bool `json:"terminalTotalDifficultyPassed,omitempty"`
 
+	// MaxTxPerBlock caps the number of transactions a block may contain, for
+	// deployments requiring one (0 = unlimited). Not used on public networks.
+	MaxTxPerBlock uint64 `json:"maxTxPerBlock,omitempty"`
+
+	// RejectZeroAddressWithdrawals makes blocks paying withdrawals to the zero
+	// address invalid, for deployments treating them as malformed. Consensus on
+	// public networks allows them, so this must stay disabled there.
+	RejectZeroAddressWithdrawals bool `json:"rejectZeroAddressWithdrawals,omitempty"`
+
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
@@ -384,6 +393,17 @@ func (c *ChainConfig) Description() string {
 	if c.PragueTime != nil {
 		banner += fmt.Sprintf(" - Prague:                      @%-10v\n", *c.PragueTime)
 	}
+	// Add a section for the non-standard chain rules, if any are configured
+	if c.MaxTxPerBlock != 0 || c.RejectZeroAddressWithdrawals {
+		banner += "\n"
+		banner += "Custom chain rules:\n"
+		if c.MaxTxPerBlock != 0 {
+			banner += fmt.Sprintf(" - Max transactions per block:  %d\n", c.MaxTxPerBlock)
+		}
+		if c.RejectZeroAddressWithdrawals {
+			banner += " - Zero address withdrawals:    rejected\n"
+		}
+	}
 	return banner
 }
 
@@ -639,6 +659,14 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headNumber *big.Int,
 	if isForkTimestampIncompatible(c.PragueTime, newcfg.PragueTime, headTimestamp) {
 		return newTimestampCompatError("Prague fork timestamp", c.PragueTime, newcfg.PragueTime)
 	}
+	// The transaction cap applies from genesis, changing it after the first block
+	// may invalidate the stored chain
+	if headNumber.Sign() > 0 && c.MaxTxPerBlock != newcfg.MaxTxPerBlock {
+		return newBlockCompatError("max transactions per block", new(big.Int), new(big.Int))
+	}
+	if c.IsShanghai(headNumber, headTimestamp) && c.RejectZeroAddressWithdrawals != newcfg.RejectZeroAddressWithdrawals {
+		return newTimestampCompatError("zero address withdrawals flag", c.ShanghaiTime, newcfg.ShanghaiTime)
+	}
 	return nil
 }
 
@@ -109,6 +109,40 @@ func TestCheckCompatible(t *testing.T) {
 				RewindToTime: 9,
 			},
 		},
+		{
+			stored:    &ChainConfig{MaxTxPerBlock: 10},
+			new:       &ChainConfig{MaxTxPerBlock: 20},
+			headBlock: 0,
+			wantErr:   nil,
+		},
+		{
+			stored:    &ChainConfig{MaxTxPerBlock: 10},
+			new:       &ChainConfig{MaxTxPerBlock: 20},
+			headBlock: 5,
+			wantErr: &ConfigCompatError{
+				What:          "max transactions per block",
+				StoredBlock:   big.NewInt(0),
+				NewBlock:      big.NewInt(0),
+				RewindToBlock: 0,
+			},
+		},
+		{
+			stored:        &ChainConfig{LondonBlock: big.NewInt(0), ShanghaiTime: newUint64(10), RejectZeroAddressWithdrawals: true},
+			new:           &ChainConfig{LondonBlock: big.NewInt(0), ShanghaiTime: newUint64(10)},
+			headTimestamp: 9,
+			wantErr:       nil,
+		},
+		{
+			stored:        &ChainConfig{LondonBlock: big.NewInt(0), ShanghaiTime: newUint64(10), RejectZeroAddressWithdrawals: true},
+			new:           &ChainConfig{LondonBlock: big.NewInt(0), ShanghaiTime: newUint64(10)},
+			headTimestamp: 25,
+			wantErr: &ConfigCompatError{
+				What:         "zero address withdrawals flag",
+				StoredTime:   newUint64(10),
+				NewTime:      newUint64(10),
+				RewindToTime: 9,
+			},
+		},
 	}
 
 	for _, test := range tests {